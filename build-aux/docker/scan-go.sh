@@ -18,12 +18,15 @@ fi
 if [[ -n "${PROPRIETARY_PACKAGES}" ]]; then
     ADDITIONAL_GENERATE_ARGS="${ADDITIONAL_GENERATE_ARGS} --proprietary-software=${PROPRIETARY_PACKAGES} "
 fi
+if [[ -n "${LICENSE_DATABASE}" ]]; then
+    ADDITIONAL_GENERATE_ARGS="${ADDITIONAL_GENERATE_ARGS} --license-database=${LICENSE_DATABASE} "
+fi
 
-/scripts/go-mkopensource --output-format=txt --package=mod --output-type=markdown --gotar="$(ls /data/go*.src.tar.gz)"  \
+/scripts/go-mkopensource --output-format=txt --package=mod --output-type=markdown \
     ${ADDITIONAL_GENERATE_ARGS} >"${GO_DEPENDENCIES}"
 
 DEPENDENCY_INFO="${BUILD_TMP}/go_dependencies.json"
 /scripts/go-mkopensource --output-format=txt --package=mod --output-type=json --application-type=${APPLICATION_TYPE} \
-    ${ADDITIONAL_GENERATE_ARGS} --gotar="$(ls /data/go*.src.tar.gz)" >"${DEPENDENCY_INFO}"
+    ${ADDITIONAL_GENERATE_ARGS} >"${DEPENDENCY_INFO}"
 
 jq -r '.licenseInfo | to_entries | .[] | "* [" + .key + "](" + .value + ")"' "${DEPENDENCY_INFO}" >"${GO_LICENSES}"
@@ -29,7 +29,12 @@ scan_npm_package() {
   license-checker --excludePackages "${PKG_NAME};${EXCLUDED_PKG}" --customPath "/scripts/customLicenseFormat.json" \
     --json >"${PACKAGE_DEPS}"
 
-  /scripts/js-mkopensource --application-type=${APPLICATION_TYPE} < <(cat "${PACKAGE_DEPS}") >"$2"
+  ADDITIONAL_GENERATE_ARGS=""
+  if [[ -f /scripts/license-database.yaml ]]; then
+    ADDITIONAL_GENERATE_ARGS="--license-database=/scripts/license-database.yaml"
+  fi
+
+  /scripts/js-mkopensource --application-type=${APPLICATION_TYPE} ${ADDITIONAL_GENERATE_ARGS} < <(cat "${PACKAGE_DEPS}") >"$2"
 
   popd >/dev/null
 }
@@ -19,6 +19,9 @@ while [ $# -gt 0 ]; do
     --proprietary-packages)
       PROPRIETARY_PACKAGES_VALUE="$2"
       ;;
+    --license-database)
+      LICENSE_DATABASE_VALUE="$2"
+      ;;
   esac
   shift
 done
@@ -51,6 +54,7 @@ docker build \
   --build-arg SCRIPTS_HOME="${SCRIPTS_HOME}" \
   --build-arg UNPARSABLE_PACKAGE="${UNPARSABLE_PACKAGE_VALUE}" \
   --build-arg PROPRIETARY_PACKAGES="${PROPRIETARY_PACKAGES_VALUE}" \
+  --build-arg LICENSE_DATABASE="${LICENSE_DATABASE_VALUE}" \
   -t "go-deps-builder" --target license_output \
   --output "${BUILD_TMP}" .
 popd >/dev/null
@@ -72,6 +76,7 @@ if [ -n "${NPM_PACKAGES}" ]; then
     --build-arg APPLICATION_TYPE="${APPLICATION_TYPE}" \
     --build-arg SCRIPTS_HOME="${SCRIPTS_HOME}" \
     --build-arg EXCLUDED_PKG="${EXCLUDED_PKG}" \
+    --build-arg LICENSE_DATABASE="${LICENSE_DATABASE_VALUE}" \
     --build-arg USER_ID="${UID}" \
     -t "js-deps-builder" \
     --target license_output \
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/datawire/go-mkopensource/pkg/mkopensource"
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
+)
+
+const (
+	// Format of the report of scanning errors
+	textErrorsFormat  = "text"
+	jsonErrorsFormat  = "json"
+	sarifErrorsFormat = "sarif"
+)
+
+// goModLocator returns a Locator that finds the line of a go.mod file
+// that requires the module that a package (or module) is in.
+func goModLocator(filename string) (scanningerrors.Locator, error) {
+	body, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	goMod, err := modfile.ParseLax(filename, body, nil)
+	if err != nil {
+		return nil, err
+	}
+	return func(dependency string) *scanningerrors.Location {
+		location := &scanningerrors.Location{File: filename}
+		longest := ""
+		for _, require := range goMod.Require {
+			modPath := require.Mod.Path
+			if (dependency == modPath || strings.HasPrefix(dependency, modPath+"/")) && len(modPath) > len(longest) {
+				longest = modPath
+				location.Line = require.Syntax.Start.Line
+			}
+		}
+		return location
+	}, nil
+}
+
+// writeErrorReport writes the --errors-format report of the scanning
+// errors of a result to the named file, or stdout if filename is empty.
+func writeErrorReport(format, filename string, result *mkopensource.Result) error {
+	locate, err := goModLocator("go.mod")
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	report := result.ErrorReport(locate)
+
+	var body []byte
+	switch format {
+	case jsonErrorsFormat:
+		body, err = report.JSON()
+	case sarifErrorsFormat:
+		body, err = report.SARIF("go-mkopensource")
+	default:
+		err = fmt.Errorf("unknown errors format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	return writeOutput(filename, func(w io.Writer) error {
+		_, err := w.Write(body)
+		return err
+	})
+}
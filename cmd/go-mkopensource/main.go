@@ -1,55 +1,63 @@
 package main
 
 import (
-	"archive/tar"
 	"bytes"
-	"compress/gzip"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
-	"os/exec"
-	"path"
 	"path/filepath"
-	"regexp"
-	"sort"
+	"runtime"
 	"strings"
-	"text/tabwriter"
 
-	"github.com/go-git/go-git/v5"
 	"github.com/spf13/pflag"
 
-	"github.com/datawire/go-mkopensource/pkg/dependencies"
+	"github.com/datawire/go-mkopensource/pkg/archive"
 	"github.com/datawire/go-mkopensource/pkg/detectlicense"
-	"github.com/datawire/go-mkopensource/pkg/golist"
-	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
+	"github.com/datawire/go-mkopensource/pkg/mkopensource"
 )
 
 type CLIArgs struct {
 	OutputFormat        string
 	OutputName          string
+	Output              string
+	TarCompression      string
+	CompleteSource      bool
 	OutputType          string
 	ApplicationType     string
 	UnparsablePackages  string
 	ProprietarySoftware string
 	GoTarFilename       string
+	GoVersion           string
+	GoLicenseFile       string
 	Package             string
 	IgnoreDirty         bool
+	VerifySources       string
+	ErrorsFormat        string
+	ErrorsOutput        string
+	SuggestOverrides    string
+	Profile             string
+	LicenseDatabases    []string
+	CacheDir            string
+	NoCache             bool
+	Jobs                int
+	Verbose             bool
 }
 
 const (
 	// Type of output to generate
-	markdownOutputType = "markdown"
-	jsonOutputType     = "json"
-
-	// Validations to do on the licenses.
-	// The only validation for "internal" is to check chat forbidden licenses are not used
-	internalApplication = "internal"
-	// "external" applications have additional license requirements as documented in
-	//https://www.notion.so/datawire/License-Management-5194ca50c9684ff4b301143806c92157
-	externalApplication = "external"
+	markdownOutputType    = "markdown"
+	jsonOutputType        = "json"
+	attributionOutputType = "attribution"
+	noticeOutputType      = "notice"
+	htmlOutputType        = "html"
+	// THIRD_PARTY_LICENSES
+	thirdPartyLicensesOutputType     = "third-party-licenses"
+	thirdPartyLicensesHTMLOutputType = "third-party-licenses-html"
+
+	// Compression of the --output-format=tar tarball
+	gzipCompression = "gzip"
+	noCompression   = "none"
 )
 
 func parseArgs() (*CLIArgs, error) {
@@ -57,20 +65,48 @@ func parseArgs() (*CLIArgs, error) {
 	argparser := pflag.NewFlagSet(os.Args[0], pflag.ContinueOnError)
 	help := false
 	argparser.BoolVarP(&help, "help", "h", false, "Show this message")
-	argparser.StringVar(&args.OutputFormat, "output-format", "", "Output format ('tar' or 'txt')")
-	argparser.StringVar(&args.OutputName, "output-name", "", "Name of the root directory in the --output-format=tar tarball")
+	argparser.StringVar(&args.OutputFormat, "output-format", "", "Output format ('tar', 'zip' or 'txt')")
+	argparser.StringVar(&args.OutputName, "output-name", "", "Name of the root directory in the --output-format=tar or zip archive")
+	argparser.StringVar(&args.Output, "output", "", "File to write the output to, instead of stdout")
+	argparser.StringVar(&args.TarCompression, "tar-compression", gzipCompression,
+		fmt.Sprintf("Compression of the --output-format=tar tarball. One of: %s, %s", gzipCompression, noCompression))
 	argparser.StringVar(&args.OutputType, "output-type", markdownOutputType,
-		fmt.Sprintf("Format used when printing dependency information. One of: %s, %s", markdownOutputType, jsonOutputType))
-	argparser.StringVar(&args.GoTarFilename, "gotar", "", "Tarball of the Go stdlib source code")
+		fmt.Sprintf("Format used when printing dependency information. One of: %s, %s, %s, %s, %s, %s, %s",
+			markdownOutputType, jsonOutputType, attributionOutputType, noticeOutputType, thirdPartyLicensesOutputType, thirdPartyLicensesHTMLOutputType, htmlOutputType))
+	argparser.BoolVar(&args.CompleteSource, "complete-source", false,
+		fmt.Sprintf("Include the complete source of weak-copyleft modules, and a %s manifest of it, in the --output-format=tar or zip archive", archive.ManifestFilename))
+	argparser.StringVar(&args.GoTarFilename, "gotar", "",
+		"Tarball of the Go stdlib source code to read the version and license of the stdlib from (default: the local Go toolchain)")
+	argparser.StringVar(&args.GoVersion, "go-version", "",
+		"Version of the Go stdlib, instead of that of the local Go toolchain or the go.mod toolchain directive. Only valid without --gotar")
+	argparser.StringVar(&args.GoLicenseFile, "go-license", "",
+		"LICENSE file of the Go stdlib, instead of that of the local Go toolchain. Only valid without --gotar")
 	argparser.StringVar(&args.Package, "package", "", "The package(s) to report library usage for")
-	argparser.StringVar(&args.ApplicationType, "application-type", externalApplication,
+	argparser.StringVar(&args.ApplicationType, "application-type", mkopensource.ExternalApplication,
 		fmt.Sprintf("Where will the application run. One of: %s, %s\n"+
-			"Internal applications are run on Ambassador servers.\n"+
-			"External applications run on customer machines", internalApplication, externalApplication))
+			"Internal applications are run on the organization's own servers.\n"+
+			"External applications run on customer machines", mkopensource.InternalApplication, mkopensource.ExternalApplication))
 	argparser.StringVar(&args.UnparsablePackages, "unparsable-packages", "",
 		"Yaml file containing SPDX License IDs for packages that have valid licenses, but cannot be parsed by this license checker")
+	argparser.StringVar(&args.SuggestOverrides, "suggest-overrides", "",
+		"If the license of any packages can't be detected, write a stub --unparsable-packages file with guesses at their licenses to this file, for a human to review")
 	argparser.StringVar(&args.ProprietarySoftware, "proprietary-software", "", "Yaml file containing proprietary packages")
+	argparser.StringVar(&args.Profile, "profile", detectlicense.DefaultProfile,
+		fmt.Sprintf("The organization that the license policy is of: one of %s, or a yaml file", strings.Join(detectlicense.ShippedProfiles(), ", ")))
+	argparser.StringArrayVar(&args.LicenseDatabases, "license-database", nil,
+		"Yaml or json license database to layer on top of the built-in one; may be given more than once")
+	argparser.StringVar(&args.CacheDir, "cache-dir", "",
+		"Directory to cache the licenses detected in each module version in (default: go-mkopensource in the user cache directory)")
+	argparser.BoolVar(&args.NoCache, "no-cache", false, "Detect the licenses of every package, without using or updating the cache")
+	argparser.IntVar(&args.Jobs, "jobs", runtime.NumCPU(), "How many packages to read and detect the licenses of at once")
 	argparser.BoolVar(&args.IgnoreDirty, "ignore-dirty", false, "ignore go mod being dirty and generate dependencies")
+	argparser.StringVar(&args.VerifySources, "verify-sources", mkopensource.VerifyError,
+		fmt.Sprintf("What to do if the files of a module don't match go.sum. One of: %s, %s, %s", mkopensource.VerifyError, mkopensource.VerifyWarn, mkopensource.VerifyOff))
+	argparser.StringVar(&args.ErrorsFormat, "errors-format", textErrorsFormat,
+		fmt.Sprintf("Also write a machine-readable report of the scanning errors, if there are any. One of: %s, %s, %s", textErrorsFormat, jsonErrorsFormat, sarifErrorsFormat))
+	argparser.StringVar(&args.ErrorsOutput, "errors-output", "", "File to write the --errors-format report to, instead of stdout")
+	argparser.BoolVar(&args.Verbose, "verbose", false,
+		fmt.Sprintf("Include the files that each license was detected in. Only valid for --output-type=%s or %s", jsonOutputType, htmlOutputType))
 
 	if err := argparser.Parse(os.Args[1:]); err != nil {
 		return nil, err
@@ -87,12 +123,19 @@ func parseArgs() (*CLIArgs, error) {
 		return nil, fmt.Errorf("expected 0 arguments, got %d: %q", argparser.NArg(), argparser.Args())
 	}
 
-	if args.OutputType != markdownOutputType && args.OutputType != jsonOutputType {
-		return nil, fmt.Errorf("--output-type must be one of '%s', '%s'", markdownOutputType, jsonOutputType)
+	switch args.OutputType {
+	case markdownOutputType, jsonOutputType, attributionOutputType, noticeOutputType, thirdPartyLicensesOutputType, thirdPartyLicensesHTMLOutputType, htmlOutputType:
+	default:
+		return nil, fmt.Errorf("--output-type must be one of '%s', '%s', '%s', '%s', '%s', '%s', '%s'",
+			markdownOutputType, jsonOutputType, attributionOutputType, noticeOutputType, thirdPartyLicensesOutputType, thirdPartyLicensesHTMLOutputType, htmlOutputType)
+	}
+
+	if args.Verbose && args.OutputType != jsonOutputType && args.OutputType != htmlOutputType {
+		return nil, fmt.Errorf("--verbose is only valid for --output-type=%s or %s", jsonOutputType, htmlOutputType)
 	}
 
-	if args.ApplicationType != internalApplication && args.ApplicationType != externalApplication {
-		return nil, fmt.Errorf("--application-type must be one of '%s', '%s'", internalApplication, externalApplication)
+	if args.ApplicationType != mkopensource.InternalApplication && args.ApplicationType != mkopensource.ExternalApplication {
+		return nil, fmt.Errorf("--application-type must be one of '%s', '%s'", mkopensource.InternalApplication, mkopensource.ExternalApplication)
 	}
 
 	switch args.OutputFormat {
@@ -100,20 +143,56 @@ func parseArgs() (*CLIArgs, error) {
 		if args.OutputName != "" {
 			return nil, errors.New("--output-name is only valid for --output-mode=tar")
 		}
-	case "tar":
+	case "tar", "zip":
 		if args.OutputName == "" {
-			return nil, errors.New("--output-name is required for --output-mode=tar")
+			return nil, fmt.Errorf("--output-name is required for --output-mode=%s", args.OutputFormat)
 		}
 		if args.OutputType != markdownOutputType {
-			return nil, fmt.Errorf("--output-type should be set to '%s' for --output-mode=tar", markdownOutputType)
+			return nil, fmt.Errorf("--output-type should be set to '%s' for --output-mode=%s", markdownOutputType, args.OutputFormat)
 		}
 
 	default:
-		return nil, errors.New("--output-format must be one of 'tar' or 'txt'")
+		return nil, errors.New("--output-format must be one of 'tar', 'zip' or 'txt'")
+	}
+
+	switch args.TarCompression {
+	case gzipCompression, noCompression:
+	default:
+		return nil, fmt.Errorf("--tar-compression must be one of '%s', '%s'", gzipCompression, noCompression)
+	}
+	if args.TarCompression != gzipCompression && args.OutputFormat != "tar" {
+		return nil, errors.New("--tar-compression is only valid for --output-format=tar")
+	}
+	switch args.VerifySources {
+	case mkopensource.VerifyError, mkopensource.VerifyWarn, mkopensource.VerifyOff:
+	default:
+		return nil, fmt.Errorf("--verify-sources must be one of '%s', '%s', '%s'", mkopensource.VerifyError, mkopensource.VerifyWarn, mkopensource.VerifyOff)
+	}
+
+	switch args.ErrorsFormat {
+	case textErrorsFormat, jsonErrorsFormat, sarifErrorsFormat:
+	default:
+		return nil, fmt.Errorf("--errors-format must be one of '%s', '%s', '%s'", textErrorsFormat, jsonErrorsFormat, sarifErrorsFormat)
+	}
+	if args.ErrorsOutput != "" && args.ErrorsFormat == textErrorsFormat {
+		return nil, fmt.Errorf("--errors-output is only valid for --errors-format=%s or %s", jsonErrorsFormat, sarifErrorsFormat)
 	}
 
-	if !strings.HasPrefix(filepath.Base(args.GoTarFilename), "go1.") || !strings.HasSuffix(args.GoTarFilename, ".tar.gz") {
-		return nil, fmt.Errorf("--gotar (%q) doesn't look like a go1.*.tar.gz file", args.GoTarFilename)
+	if args.Jobs < 1 {
+		return nil, fmt.Errorf("--jobs (%d) must be at least 1", args.Jobs)
+	}
+
+	if args.CompleteSource && args.OutputFormat == "txt" {
+		return nil, errors.New("--complete-source is only valid for --output-format=tar or zip")
+	}
+
+	if args.GoTarFilename != "" {
+		if !strings.HasPrefix(filepath.Base(args.GoTarFilename), "go1.") || !strings.HasSuffix(args.GoTarFilename, ".tar.gz") {
+			return nil, fmt.Errorf("--gotar (%q) doesn't look like a go1.*.tar.gz file", args.GoTarFilename)
+		}
+		if args.GoVersion != "" || args.GoLicenseFile != "" {
+			return nil, errors.New("--go-version and --go-license are only valid without --gotar")
+		}
 	}
 	if args.Package == "" {
 		return nil, fmt.Errorf("--package (%q) must be non-empty", args.Package)
@@ -123,6 +202,10 @@ func parseArgs() (*CLIArgs, error) {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "review" {
+		reviewMain()
+		return
+	}
 	args, err := parseArgs()
 	if err != nil {
 		if err == pflag.ErrHelp {
@@ -137,330 +220,126 @@ func main() {
 	}
 }
 
-func loadGoTar(goTarFilename string) (version string, license []byte, err error) {
-	goTarFile, err := os.Open(goTarFilename)
-	if err != nil {
-		return "", nil, err
-	}
-	defer func() { _ = goTarFile.Close() }()
-	goTarUncompressed, err := gzip.NewReader(goTarFile)
-	if err != nil {
-		return "", nil, err
+// openCache returns the cache of detected licenses to use, or nil if
+// the cache is disabled or can't be used; the cache is only an
+// optimization, so that isn't an error.
+func openCache(args *CLIArgs) *detectlicense.Cache {
+	if args.NoCache {
+		return nil
 	}
-	defer func() { _ = goTarUncompressed.Close() }()
-	goTar := tar.NewReader(goTarUncompressed)
-	vrx := regexp.MustCompile(`go(\d+\.\d+\.\d+(?:-\S+)?)`)
-	for {
-		header, err := goTar.Next()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return "", nil, err
+	dir := args.CacheDir
+	if dir == "" {
+		var err error
+		if dir, err = detectlicense.DefaultCacheDir(); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: warning: not caching detected licenses: %v\n", os.Args[0], err)
+			return nil
 		}
-		switch header.Name {
-		case "go/VERSION":
-			fc, err := io.ReadAll(goTar)
-			if err != nil {
-				return "", nil, err
-			}
-			if m := vrx.FindStringSubmatch(string(fc)); len(m) == 2 {
-				version = "v" + m[1]
-			}
-		case "go/LICENSE":
-			fc, err := io.ReadAll(goTar)
-			if err != nil {
-				return "", nil, err
-			}
-			license = fc
-		}
-		if version != "" && license != nil {
-			break
-		}
-	}
-	if version == "" || license == nil {
-		return "", nil, fmt.Errorf("file %q did not contain %q or %q", goTarFilename, "go/VERSION", "go/LICENSE")
 	}
-	return version, license, nil
-}
-
-func isAmbassadorProprietary(licenses map[detectlicense.License]struct{}) bool {
-	_, ok := licenses[detectlicense.AmbassadorProprietary]
-	return ok
-}
-
-func licenseIsWeakCopyleft(licenses map[detectlicense.License]struct{}) bool {
-	for license := range licenses {
-		if license.WeakCopyleft {
-			return true
-		}
+	cache, err := detectlicense.OpenCache(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: warning: not caching detected licenses: %v\n", os.Args[0], err)
+		return nil
 	}
-	return false
+	return cache
 }
 
 func Main(args *CLIArgs) error {
-	// Let's do the expensive stuff (stuff that isn't entirely
-	// in-memory) up-front.
-
-	// `tar xf go{version}.src.tar.gz`
-	goVersion, goLicense, err := loadGoTar(args.GoTarFilename)
+	profile, err := detectlicense.LoadProfile(args.Profile)
 	if err != nil {
 		return err
 	}
-
-	if err := tidyGoModFile(); err != nil {
+	database, err := detectlicense.LoadDatabase(args.LicenseDatabases...)
+	if err != nil {
 		return err
 	}
 
-	// `go list`
-	var mainMods map[string]struct{}
-	var listPkgs []golist.Package
-	if args.Package == "mod" {
-		// `go list`
-		listPkgs, err = VendorList()
-		if err != nil {
-			return err
-		}
-		listPkgs = append(listPkgs, golist.Package{}) // stdlib
-
-		// `go list -m`
-		cmd := exec.Command("go", "list", "-m")
-		cmd.Stderr = os.Stderr
-		modname, err := cmd.Output()
-		if err != nil {
-			return err
-		}
-		mainMods = make(map[string]struct{}, 1)
-		mainMods[strings.TrimSpace(string(modname))] = struct{}{}
-	} else {
-		// `go list`
-		listPkgs, err = golist.GoListPackages([]string{"-deps"}, []string{args.Package})
-		if err != nil {
+	proprietarySoftware := profile.Proprietary()
+	if args.ProprietarySoftware != "" {
+		if err := proprietarySoftware.ReadProprietarySoftwareFile(args.ProprietarySoftware); err != nil {
 			return err
 		}
-		// `go list -m` (fast: in-memory)
-		mainMods = make(map[string]struct{})
-		for _, pkg := range listPkgs {
-			if !pkg.DepOnly && pkg.Module != nil {
-				mainMods[pkg.Module.Path] = struct{}{}
-			}
-		}
-	}
-
-	// `go mod vendor`
-	fs := newFSCache()
-	pkgFiles := make(map[string]map[string][]byte)
-	for _, pkg := range listPkgs {
-		vendor := make(map[string][]byte)
-		if pkg.Module == nil {
-			// standard library
-			vendor["std/LICENSE"] = goLicense
-		} else {
-			// module
-			if _, isMainMod := mainMods[pkg.Module.Path]; isMainMod {
-				continue
-			}
-			if args.Package == "mod" {
-				if err := fs.collectVendoredPkg(vendor, pkg); err != nil {
-					return err
-				}
-			} else {
-				if err := fs.collectPkg(vendor, pkg); err != nil {
-					return err
-				}
-			}
-		}
-		pkgFiles[pkg.ImportPath] = vendor
-	}
-
-	// From this point on, everything should be entirely in-memory
-	// (besides writing the output file, I guess).
-
-	// Figure out the license(s) that apply to each package.  We
-	// sort the packages first so that if there's an error, which
-	// error the user sees is deterministic.
-	pkgNames := make([]string, 0, len(pkgFiles))
-	for pkgName := range pkgFiles {
-		pkgNames = append(pkgNames, pkgName)
 	}
 
-	pkgVersions := map[string]string{}
-	for _, pkg := range listPkgs {
-		if pkg.Module != nil {
-			pkgVersions[pkg.ImportPath] = pkg.Module.Version
-		}
+	scanner, err := mkopensource.NewScanner(mkopensource.Options{
+		Package:             args.Package,
+		GoTarFilename:       args.GoTarFilename,
+		GoVersion:           args.GoVersion,
+		GoLicenseFile:       args.GoLicenseFile,
+		ApplicationType:     args.ApplicationType,
+		UnparsablePackages:  args.UnparsablePackages,
+		Database:            database,
+		Profile:             profile,
+		ProprietarySoftware: proprietarySoftware,
+		VerifySources:       args.VerifySources,
+		Cache:               openCache(args),
+		Jobs:                args.Jobs,
+		Evidence:            args.Verbose,
+	})
+	if err != nil {
+		return err
 	}
-
-	sort.Strings(pkgNames)
-	pkgLicenses := make(map[string]map[detectlicense.License]struct{})
-	licErrs := []error(nil)
-
-	var unparsablePackages map[string]map[detectlicense.License]struct{}
-	if args.UnparsablePackages != "" {
-		if unparsablePackages, err = detectlicense.ReadPackageLicensesFromFile(args.UnparsablePackages); err != nil {
-			return err
-		}
+	result, err := scanner.Scan()
+	if err != nil {
+		return err
 	}
-
-	ambProprietarySoftware := detectlicense.GetAmbassadorProprietarySoftware()
-	if args.ProprietarySoftware != "" {
-		err = ambProprietarySoftware.ReadProprietarySoftwareFile(args.ProprietarySoftware)
-		if err != nil {
-			return err
-		}
+	for _, warning := range result.Warnings {
+		_, _ = fmt.Fprintf(os.Stderr, "WARNING: %v\n", warning)
 	}
 
-	for _, pkgName := range pkgNames {
-		if ambProprietarySoftware.IsProprietarySoftware(pkgName) {
-			// Ambassador's proprietary software has a proprietary license
-			pkgLicenses[pkgName] = map[detectlicense.License]struct{}{detectlicense.AmbassadorProprietary: {}}
-			continue
-		}
-
-		pkgLicenses[pkgName], err = detectlicense.DetectLicenses(pkgName, pkgVersions[pkgName], pkgFiles[pkgName])
-		if err != nil {
-			if licenses, ok := unparsablePackages[pkgName]; ok {
-				pkgLicenses[pkgName] = licenses
-			} else {
-				err = fmt.Errorf(`Package %q: %w`, pkgName, err)
-				licErrs = append(licErrs, err)
-			}
-		} else {
-			if _, ok := unparsablePackages[pkgName]; ok {
-				licErrs = append(licErrs, fmt.Errorf(`Package %q has a valid license. It must be removed from %s`,
-					pkgName, args.UnparsablePackages))
+	if licErrs := result.Errors; len(licErrs) > 0 {
+		if args.ErrorsFormat != "" && args.ErrorsFormat != textErrorsFormat {
+			if err := writeErrorReport(args.ErrorsFormat, args.ErrorsOutput, result); err != nil {
+				return err
 			}
 		}
-	}
-
-	// Group packages by module & collect module info
-	modPkgs := make(map[string][]string)
-	modInfos := make(map[string]*golist.Module)
-	modLicenses := make(map[string]map[detectlicense.License]struct{})
-	modNames := make([]string, 0, len(modPkgs))
-	for _, pkg := range listPkgs {
-		key := "<nil>"
-		if pkg.Module != nil {
-			key = pkg.Module.Path
-		}
-		if _, isMainMod := mainMods[key]; isMainMod {
-			continue
-		}
-		modPkgs[key] = append(modPkgs[key], pkg.ImportPath)
-		if _, done := modInfos[key]; !done {
-			modInfos[key] = pkg.Module
-			modLicenses[key] = make(map[detectlicense.License]struct{})
-			modNames = append(modNames, key)
-		}
-		for license := range pkgLicenses[pkg.ImportPath] {
-			modLicenses[key][license] = struct{}{}
-		}
-	}
-	sort.Strings(modNames)
-
-	// Figure out how to pronounce "X" in "X incorporates Free and
-	// Open Source software".
-	var mainCmdPkgs []string
-	var mainLibPkgs []string
-	for _, pkg := range listPkgs {
-		if pkg.Module == nil {
-			continue
-		}
-		if _, isMainMod := mainMods[pkg.Module.Path]; !isMainMod {
-			continue
-		}
-		if pkg.DepOnly {
-			continue
-		}
-		if pkg.Name == "main" {
-			mainCmdPkgs = append(mainCmdPkgs, pkg.ImportPath)
-		} else {
-			mainLibPkgs = append(mainLibPkgs, pkg.ImportPath)
+		if args.SuggestOverrides != "" {
+			if suggestions := suggestOverrides(licErrs, result.PackageFiles); len(suggestions) > 0 {
+				if err := writeOutput(args.SuggestOverrides, func(w io.Writer) error {
+					return writeOverridesStub(w, suggestions)
+				}); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Wrote suggested --unparsable-packages entries to %s; review them before using them\n", args.SuggestOverrides)
+			}
 		}
-	}
-	sort.Strings(mainCmdPkgs)
-	sort.Strings(mainLibPkgs)
-
-	// Generate the readme file.
-	licenseRestriction := getLicenseRestriction(args.ApplicationType)
-
-	dependencyList, licenseErrors := GenerateDependencyList(modNames, modLicenses, modInfos, goVersion, licenseRestriction)
-	licErrs = append(licErrs, licenseErrors...)
-	if len(licErrs) > 0 {
-		return scanningerrors.ExplainErrors(licErrs)
+		return result.ExplainErrors()
 	}
 
 	switch args.OutputFormat {
 	case "txt":
-		readme, generationErr := generateOutput(args.Package, args.OutputFormat, args.OutputType, mainMods, mainLibPkgs, mainCmdPkgs, dependencyList)
-		if generationErr != nil {
-			return generationErr
-		}
-
-		if _, err := readme.WriteTo(os.Stdout); err != nil {
+		readme, err := generateOutput(args.OutputType, result, profile)
+		if err != nil {
 			return err
 		}
-	case "tar":
-		// Build a listing of all files to go in to the tarball
-		readme, generationErr := generateOutput(args.Package, args.OutputFormat, markdownOutputType, mainMods, mainLibPkgs, mainCmdPkgs, dependencyList)
-		if generationErr != nil {
-			return generationErr
+		if err := writeOutput(args.Output, func(w io.Writer) error {
+			_, err := readme.WriteTo(w)
+			return err
+		}); err != nil {
+			return err
 		}
-
-		tarFiles := make(map[string][]byte)
-		tarFiles["DEPENDENCIES.md"] = readme.Bytes()
-		for pkgName := range pkgFiles {
-			ambassadorProprietary := isAmbassadorProprietary(pkgLicenses[pkgName])
-			switch {
-			case ambassadorProprietary:
-				// don't include anything
-			case licenseIsWeakCopyleft(pkgLicenses[pkgName]):
-				// include everything
-				for filename, fileBody := range pkgFiles[pkgName] {
-					tarFiles[filename] = fileBody
-				}
-			default:
-				// just include metadata
-				for filename, fileBody := range pkgFiles[pkgName] {
-					if matchMetadata(filename) {
-						tarFiles[filename] = fileBody
-					}
-				}
-			}
+	case "tar", "zip":
+		tarFiles, err := result.ArchiveFiles(args.CompleteSource)
+		if err != nil {
+			return err
 		}
 
 		// Write output
-		outputFile := os.Stdout
-		defer func() { _ = outputFile.Close() }()
-		outputCompressed := gzip.NewWriter(outputFile)
-		defer func() { _ = outputCompressed.Close() }()
-		outputTar := tar.NewWriter(outputCompressed)
-		defer func() { _ = outputTar.Close() }()
-
-		filenames := make([]string, 0, len(tarFiles))
-		for filename := range tarFiles {
-			filenames = append(filenames, filename)
+		mtime, err := archive.SourceDateEpoch()
+		if err != nil {
+			return err
 		}
-		sort.Strings(filenames)
-		for _, filename := range filenames {
-			body := tarFiles[filename]
-			err := outputTar.WriteHeader(&tar.Header{
-				Typeflag: tar.TypeReg,
-				Name:     args.OutputName + "/" + filename,
-				Size:     int64(len(body)),
-				Mode:     0644,
-			})
-			if err != nil {
-				return err
-			}
-			if _, err := outputTar.Write(body); err != nil {
-				return err
+		if err := writeOutput(args.Output, func(w io.Writer) error {
+			if args.OutputFormat == "zip" {
+				return archive.WriteZip(w, args.OutputName, tarFiles, mtime)
 			}
+			return archive.WriteTar(w, args.OutputName, tarFiles, mtime, args.TarCompression != noCompression)
+		}); err != nil {
+			return err
 		}
 	}
 
 	if !args.IgnoreDirty {
-		isDirty, err := isGoModDirty()
+		isDirty, err := scanner.GoModDirty()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "WARNING: could not verify if go.mod or go.sum are dirty: %s.\n", err.Error())
 		}
@@ -473,134 +352,54 @@ func Main(args *CLIArgs) error {
 	return nil
 }
 
-func tidyGoModFile() error {
-	tidyCmd := exec.Command("go", "mod", "tidy")
-	out, err := tidyCmd.CombinedOutput()
-	if err != nil {
-		log.Printf("'go mod tidy' failed:\n%s\n", out)
-		return fmt.Errorf("'go mod tidy' failed: %w", err)
-	}
-	return nil
-}
-
-func isGoModDirty() (result bool, err error) {
-	var repo *git.Repository
-	if repo, err = git.PlainOpen("."); err != nil {
-		return false, err
+// writeOutput calls write with the file that the output should go to:
+// the named file, or stdout if filename is empty.  A partially-written
+// file is removed if write fails.
+func writeOutput(filename string, write func(w io.Writer) error) error {
+	if filename == "" {
+		return write(os.Stdout)
 	}
-
-	var worktree *git.Worktree
-	if worktree, err = repo.Worktree(); err != nil {
-		return false, err
-	}
-
-	var status git.Status
-	if status, err = worktree.Status(); err != nil {
-		return false, err
-	}
-
-	if status.File("go.mod").Worktree == git.Modified {
-		return true, nil
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
 	}
-
-	if status.File("go.sum").Worktree == git.Modified {
-		return true, nil
+	if err := write(file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(filename)
+		return err
 	}
-
-	return false, nil
-}
-
-func getLicenseRestriction(applicationType string) detectlicense.LicenseRestriction {
-	var LicenseRestriction detectlicense.LicenseRestriction
-	switch applicationType {
-	case internalApplication:
-		LicenseRestriction = detectlicense.AmbassadorServers
-	default:
-		LicenseRestriction = detectlicense.Unrestricted
+	if err := file.Close(); err != nil {
+		_ = os.Remove(filename)
+		return err
 	}
-	return LicenseRestriction
+	return nil
 }
 
-func generateOutput(packages string, outputFormat string, outputType string, mainMods map[string]struct{}, mainLibPkgs []string, mainCmdPkgs []string, dependencyList dependencies.DependencyInfo) (*bytes.Buffer, error) {
+// generateOutput renders the --output-type report of a scan.
+func generateOutput(outputType string, result *mkopensource.Result, profile *detectlicense.Profile) (*bytes.Buffer, error) {
 	output := new(bytes.Buffer)
+	var err error
 	switch outputType {
 	case jsonOutputType:
-		err := jsonOutput(output, dependencyList)
-		if err != nil {
-			return nil, err
-		}
+		if err := result.WriteJSON(output); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(int(MarshallJsonError))
+		}
+	case htmlOutputType:
+		err = result.WriteHTML(output, profile.InternalUseLabel)
+	case attributionOutputType:
+		err = result.WriteAttribution(output)
+	case noticeOutputType:
+		err = result.WriteNotice(output)
+	case thirdPartyLicensesOutputType:
+		err = result.WriteThirdPartyLicenses(output)
+	case thirdPartyLicensesHTMLOutputType:
+		err = result.WriteThirdPartyLicensesHTML(output)
 	default:
-		markdownHeader(packages, mainMods, output, mainLibPkgs, mainCmdPkgs)
-		output.WriteString("\n")
-
-		err := markdownOutput(output, dependencyList)
-		if err != nil {
-			return nil, err
-		}
+		err = result.WriteMarkdown(output)
 	}
-
-	if outputFormat == "tar" {
-		output.WriteString("\n")
-		output.WriteString(scanningerrors.Wordwrap(0, 75, "The appropriate license notices and source code are in correspondingly named directories.") + "\n")
+	if err != nil {
+		return nil, err
 	}
 	return output, nil
 }
-
-func markdownHeader(packages string, mainMods map[string]struct{}, readme *bytes.Buffer, mainLibPkgs []string, mainCmdPkgs []string) {
-	if packages == "mod" {
-		modnames := make([]string, 0, len(mainMods))
-		for modname := range mainMods {
-			modnames = append(modnames, modname)
-		}
-		if len(mainMods) == 1 {
-			readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The Go module %q incorporates the following Free and Open Source software:", modnames[0])) + "\n")
-		} else {
-			sort.Strings(modnames)
-			readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The Go modules %q incorporate the following Free and Open Source software:", modnames)) + "\n")
-		}
-		return
-	}
-
-	if len(mainLibPkgs) == 0 {
-		if len(mainCmdPkgs) == 1 {
-			readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The program %q incorporates the following Free and Open Source software:", path.Base(mainCmdPkgs[0]))) + "\n")
-		} else {
-			readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The programs %q incorporate the following Free and Open Source software:", packages)) + "\n")
-		}
-		return
-	}
-
-	if len(mainLibPkgs) == 1 {
-		readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The Package %q incorporates the following Free and Open Source software:", mainLibPkgs[0])) + "\n")
-	} else {
-		readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The Packages %q incorporate the following Free and Open Source software:", packages)) + "\n")
-	}
-}
-
-func markdownOutput(readme *bytes.Buffer, dependencyList dependencies.DependencyInfo) error {
-	table := tabwriter.NewWriter(readme, 0, 8, 2, ' ', 0)
-	_, _ = io.WriteString(table, "  \tName\tVersion\tLicense(s)\n")
-	_, _ = io.WriteString(table, "  \t----\t-------\t----------\n")
-
-	for _, dependency := range dependencyList.Dependencies {
-		depLicenses := strings.Join(dependency.Licenses, ", ")
-		if depLicenses == "" {
-			panic(fmt.Errorf("this should not happen: empty license string for %q", dependency.Name))
-		}
-
-		_, _ = fmt.Fprintf(table, "\t%s\t%s\t%s\n", dependency.Name, dependency.Version, depLicenses)
-	}
-	_ = table.Flush()
-	return nil
-}
-
-func jsonOutput(readme *bytes.Buffer, dependencyList dependencies.DependencyInfo) error {
-	jsonString, marshallErr := json.Marshal(dependencyList)
-	if marshallErr != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Could not generate JSON output: %v\n", marshallErr)
-		os.Exit(int(MarshallJsonError))
-	}
-
-	readme.Write(jsonString)
-	return nil
-}
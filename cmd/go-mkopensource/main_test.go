@@ -2,23 +2,42 @@ package main_test
 
 import (
 	"archive/tar"
+	"archive/zip"
+	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/datawire/go-mkopensource/pkg/dependencies"
 
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	main "github.com/datawire/go-mkopensource/cmd/go-mkopensource"
 )
 
+func TestMain(m *testing.M) {
+	// Keep the cache of detected licenses out of the user's cache
+	// directory.
+	cacheDir, err := os.MkdirTemp("", "go-mkopensource-cache.")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CACHE_HOME", cacheDir)
+	code := m.Run()
+	_ = os.RemoveAll(cacheDir)
+	os.Exit(code)
+}
+
 func TestSuccessfulMarkdownOutput(t *testing.T) {
 	testCases := []struct {
 		testName                string
@@ -191,6 +210,135 @@ func TestSuccessfulJsonOutput(t *testing.T) {
 	}
 }
 
+func TestSuccessfulVerboseJsonOutput(t *testing.T) {
+	workingDir := getWorkingDir(t)
+	defer func() {
+		require.NoError(t, os.Chdir(workingDir))
+	}()
+
+	require.NoError(t, os.Chdir("testdata/01-intern-new"))
+
+	originalStdOut, r, w := interceptStdOut()
+	defer func() {
+		os.Stdout = originalStdOut
+	}()
+
+	actErr := main.Main(&main.CLIArgs{
+		OutputFormat:    "txt",
+		GoTarFilename:   filepath.Join("..", "go1.17.3-testdata.src.tar.gz"),
+		Package:         "mod",
+		OutputType:      "json",
+		ApplicationType: "external",
+		Verbose:         true,
+	})
+
+	_ = w.Close()
+
+	require.NoError(t, actErr)
+
+	jsonOutput := getDependencyInfoFromReader(t, r)
+	expectedJson := getDependencyInfoFromFile(t, "expected_verbose_json_output.json")
+
+	assert.Equal(t, expectedJson, jsonOutput)
+}
+
+func TestSuccessfulAttributionAndNoticeOutput(t *testing.T) {
+	testCases := []struct {
+		testName       string
+		testData       string
+		outputType     string
+		verbose        bool
+		expectedOutput string
+	}{
+		{
+			testName:       "Attribution output",
+			testData:       "testdata/06-multiple-licenses",
+			outputType:     "attribution",
+			expectedOutput: "expected_attribution_output.txt",
+		},
+		{
+			testName:       "NOTICE output",
+			testData:       "testdata/06-multiple-licenses",
+			outputType:     "notice",
+			expectedOutput: "expected_notice_output.txt",
+		},
+		{
+			testName:       "NOTICE output without any NOTICE files",
+			testData:       "testdata/01-intern-new",
+			outputType:     "notice",
+			expectedOutput: "expected_notice_output.txt",
+		},
+		{
+			testName:       "THIRD_PARTY_LICENSES output",
+			testData:       "testdata/06-multiple-licenses",
+			outputType:     "third-party-licenses",
+			expectedOutput: "expected_third_party_licenses_output.txt",
+		},
+		{
+			testName:       "THIRD_PARTY_LICENSES output for an overridden license",
+			testData:       "testdata/01-intern-new",
+			outputType:     "third-party-licenses",
+			expectedOutput: "expected_third_party_licenses_output.txt",
+		},
+		{
+			testName:       "THIRD_PARTY_LICENSES HTML output",
+			testData:       "testdata/06-multiple-licenses",
+			outputType:     "third-party-licenses-html",
+			expectedOutput: "expected_third_party_licenses_output.html",
+		},
+		{
+			testName:       "HTML output",
+			testData:       "testdata/06-multiple-licenses",
+			outputType:     "html",
+			expectedOutput: "expected_html_output.html",
+		},
+		{
+			testName:       "Verbose HTML output",
+			testData:       "testdata/01-intern-new",
+			outputType:     "html",
+			verbose:        true,
+			expectedOutput: "expected_verbose_html_output.html",
+		},
+	}
+
+	workingDir := getWorkingDir(t)
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			defer func() {
+				require.NoError(t, os.Chdir(workingDir))
+			}()
+
+			require.NoError(t, os.Chdir(testCase.testData))
+
+			originalStdOut, r, w := interceptStdOut()
+			defer func() {
+				os.Stdout = originalStdOut
+			}()
+
+			actErr := main.Main(&main.CLIArgs{
+				OutputFormat:    "txt",
+				GoTarFilename:   filepath.Join("..", "go1.17.3-testdata.src.tar.gz"),
+				Package:         "mod",
+				OutputType:      testCase.outputType,
+				ApplicationType: "external",
+				Verbose:         testCase.verbose,
+			})
+
+			_ = w.Close()
+
+			require.NoError(t, actErr)
+
+			programOutput, readErr := io.ReadAll(r)
+			require.NoError(t, readErr)
+
+			expectedOutput := getFileContents(t, testCase.expectedOutput)
+
+			assert.Equal(t, string(expectedOutput), string(programOutput))
+		})
+	}
+}
+
 func TestSuccessfulTarOutput(t *testing.T) {
 	testCases := []struct {
 		testName                string
@@ -278,6 +426,512 @@ func TestSuccessfulTarOutput(t *testing.T) {
 	}
 }
 
+func TestReproducibleArchiveOutput(t *testing.T) {
+	testCases := []struct {
+		testName       string
+		outputFormat   string
+		tarCompression string
+	}{
+		{
+			testName:       "gzip-compressed tarball",
+			outputFormat:   "tar",
+			tarCompression: "gzip",
+		},
+		{
+			testName:       "uncompressed tarball",
+			outputFormat:   "tar",
+			tarCompression: "none",
+		},
+		{
+			testName:     "zip file",
+			outputFormat: "zip",
+		},
+	}
+
+	workingDir := getWorkingDir(t)
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	sourceDate := time.Unix(1700000000, 0)
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			defer func() {
+				require.NoError(t, os.Chdir(workingDir))
+			}()
+
+			require.NoError(t, os.Chdir("testdata/04-nodeps"))
+
+			outputs := make([][]byte, 2)
+			for i := range outputs {
+				output := filepath.Join(t.TempDir(), "opensource.archive")
+				actErr := main.Main(&main.CLIArgs{
+					OutputFormat:    testCase.outputFormat,
+					OutputName:      "opensource",
+					Output:          output,
+					TarCompression:  testCase.tarCompression,
+					GoTarFilename:   filepath.Join("..", "go1.17.3-testdata.src.tar.gz"),
+					Package:         "mod",
+					ApplicationType: "external",
+				})
+				require.NoError(t, actErr)
+
+				var err error
+				outputs[i], err = os.ReadFile(output)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, outputs[0], outputs[1], "output is not reproducible")
+
+			var names []string
+			switch testCase.outputFormat {
+			case "zip":
+				zipFile, err := zip.NewReader(bytes.NewReader(outputs[0]), int64(len(outputs[0])))
+				require.NoError(t, err)
+				for _, file := range zipFile.File {
+					assert.True(t, file.Modified.Equal(sourceDate), "%s: wrong mtime %v", file.Name, file.Modified)
+					names = append(names, file.Name)
+				}
+			default:
+				tarBytes := outputs[0]
+				if testCase.tarCompression == "gzip" {
+					gzipFile, err := gzip.NewReader(bytes.NewReader(tarBytes))
+					require.NoError(t, err)
+					assert.True(t, gzipFile.ModTime.Equal(sourceDate), "wrong gzip mtime %v", gzipFile.ModTime)
+					tarBytes, err = io.ReadAll(gzipFile)
+					require.NoError(t, err)
+				}
+				// POSIX (USTAR/PAX) magic, as opposed to the GNU format's "ustar  \x00".
+				assert.Equal(t, "ustar\x0000", string(tarBytes[257:265]))
+				tarFile := tar.NewReader(bytes.NewReader(tarBytes))
+				for {
+					header, err := tarFile.Next()
+					if err == io.EOF {
+						break
+					}
+					require.NoError(t, err)
+					assert.True(t, header.ModTime.Equal(sourceDate), "%s: wrong mtime %v", header.Name, header.ModTime)
+					assert.Equal(t, "root", header.Uname)
+					assert.Equal(t, "root", header.Gname)
+					assert.Equal(t, 0, header.Uid)
+					assert.Equal(t, 0, header.Gid)
+					names = append(names, header.Name)
+				}
+			}
+			assert.Equal(t, []string{
+				"opensource/",
+				"opensource/DEPENDENCIES.md",
+				"opensource/std/",
+				"opensource/std/LICENSE",
+			}, names)
+		})
+	}
+}
+
+func TestCompleteSourceOutput(t *testing.T) {
+	workingDir := getWorkingDir(t)
+	defer func() {
+		require.NoError(t, os.Chdir(workingDir))
+	}()
+
+	require.NoError(t, os.Chdir("testdata/10-complete-source"))
+
+	output := filepath.Join(t.TempDir(), "opensource.tar.gz")
+	actErr := main.Main(&main.CLIArgs{
+		OutputFormat:    "tar",
+		Output:          output,
+		CompleteSource:  true,
+		GoTarFilename:   filepath.Join("..", "go1.17.3-testdata.src.tar.gz"),
+		Package:         "mod",
+		ApplicationType: "external",
+	})
+	require.NoError(t, actErr)
+
+	// The whole of each MPL module is included, not just the files
+	// that get compiled.
+	r, err := os.Open(output)
+	require.NoError(t, err)
+	defer r.Close()
+	fileContents, err := listTarContents(t, r)
+	require.NoError(t, err)
+	assert.Equal(t, string(getFileContents(t, "expected_complete_source_tar_contents.txt")), fileContents)
+
+	_, err = r.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	gzipFile, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	tarFile := tar.NewReader(gzipFile)
+	for {
+		header, err := tarFile.Next()
+		require.NoError(t, err)
+		if header.Name == "/SOURCES.json" {
+			break
+		}
+	}
+	manifest, err := io.ReadAll(tarFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(getFileContents(t, "expected_sources.json")), string(manifest))
+}
+
+func TestVerifySources(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		packagesFlag  string
+		verifySources string
+		expectedError string
+	}{
+		{
+			testName:      "vendor - error",
+			packagesFlag:  "mod",
+			expectedError: "vendor/github.com/josharian/intern/intern.go",
+		},
+		{
+			testName:      "module cache - error",
+			packagesFlag:  "./...",
+			expectedError: "checksum mismatch",
+		},
+		{
+			testName:      "vendor - warn",
+			packagesFlag:  "mod",
+			verifySources: "warn",
+		},
+		{
+			testName:      "vendor - off",
+			packagesFlag:  "mod",
+			verifySources: "off",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			workingDir := getWorkingDir(t)
+			defer func() {
+				require.NoError(t, os.Chdir(workingDir))
+			}()
+
+			require.NoError(t, os.Chdir("testdata/01-intern-new"))
+
+			// Use a module cache of our own, with a locally edited
+			// copy of the module.
+			realModCache, err := exec.Command("go", "env", "GOMODCACHE").Output()
+			require.NoError(t, err)
+			modCache := t.TempDir()
+			downloads := filepath.Join("cache", "download", "github.com", "josharian", "intern", "@v")
+			require.NoError(t, os.MkdirAll(filepath.Join(modCache, downloads), 0o755))
+			for _, ext := range []string{".info", ".mod", ".zip", ".ziphash"} {
+				filename := filepath.Join(downloads, "v1.0.1-0.20211109044230-42b52b674af5"+ext)
+				body, err := os.ReadFile(filepath.Join(strings.TrimSpace(string(realModCache)), filename))
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(filepath.Join(modCache, filename), body, 0o644))
+			}
+			t.Setenv("GOMODCACHE", modCache)
+			t.Setenv("GOPROXY", "off")
+			t.Setenv("GOFLAGS", "-mod=mod")
+			t.Cleanup(func() {
+				_ = exec.Command("go", "clean", "-modcache").Run()
+			})
+			out, err := exec.Command("go", "mod", "download", "-json", "github.com/josharian/intern@v1.0.1-0.20211109044230-42b52b674af5").Output()
+			require.NoError(t, err)
+			var download struct{ Dir string }
+			require.NoError(t, json.Unmarshal(out, &download))
+			require.NoError(t, os.Chmod(download.Dir, 0o755))
+			filename := filepath.Join(download.Dir, "intern.go")
+			require.NoError(t, os.Chmod(filename, 0o644))
+			require.NoError(t, os.WriteFile(filename, []byte("package intern\n"), 0o644))
+
+			originalStdOut, r, w := interceptStdOut()
+			defer func() {
+				os.Stdout = originalStdOut
+			}()
+
+			actErr := main.Main(&main.CLIArgs{
+				OutputFormat:    "txt",
+				GoTarFilename:   filepath.Join("..", "go1.17.3-testdata.src.tar.gz"),
+				Package:         testCase.packagesFlag,
+				OutputType:      "json",
+				ApplicationType: "external",
+				VerifySources:   testCase.verifySources,
+				IgnoreDirty:     true,
+			})
+
+			_ = w.Close()
+
+			if testCase.expectedError != "" {
+				require.Error(t, actErr)
+				assert.Contains(t, actErr.Error(), testCase.expectedError)
+				return
+			}
+			require.NoError(t, actErr)
+
+			// The report still gets generated, but without a sum for
+			// the module that could not be verified.
+			jsonOutput := getDependencyInfoFromReader(t, r)
+			require.Len(t, jsonOutput.Dependencies, 2)
+			assert.Equal(t, "github.com/josharian/intern", jsonOutput.Dependencies[1].Name)
+			assert.Empty(t, jsonOutput.Dependencies[1].Sum)
+		})
+	}
+}
+
+func TestErrorReport(t *testing.T) {
+	testCases := []struct {
+		errorsFormat string
+		expectedFile string
+	}{
+		{
+			errorsFormat: "json",
+			expectedFile: "expected_errors.json",
+		},
+		{
+			errorsFormat: "sarif",
+			expectedFile: "expected_errors.sarif",
+		},
+	}
+
+	workingDir := getWorkingDir(t)
+
+	for _, testCase := range testCases {
+		t.Run(testCase.errorsFormat, func(t *testing.T) {
+			defer func() {
+				require.NoError(t, os.Chdir(workingDir))
+			}()
+
+			require.NoError(t, os.Chdir("testdata/03-multierror"))
+
+			errorsOutput := filepath.Join(t.TempDir(), testCase.expectedFile)
+			actErr := main.Main(&main.CLIArgs{
+				OutputFormat:    "txt",
+				GoTarFilename:   filepath.Join("..", "go1.17.3-testdata.src.tar.gz"),
+				Package:         "mod",
+				OutputType:      "markdown",
+				ApplicationType: "external",
+				ErrorsFormat:    testCase.errorsFormat,
+				ErrorsOutput:    errorsOutput,
+			})
+
+			// The human-readable explanation is still returned
+			require.Error(t, actErr)
+			assert.Equal(t, string(getFileContents(t, "expected_err.txt")), actErr.Error())
+
+			report, err := os.ReadFile(errorsOutput)
+			require.NoError(t, err)
+			assert.Equal(t, string(getFileContents(t, testCase.expectedFile)), string(report))
+		})
+	}
+}
+
+func TestSuggestOverrides(t *testing.T) {
+	testCases := []struct {
+		testName     string
+		testData     string
+		expectedFile string
+	}{
+		{
+			testName:     "detection errors",
+			testData:     "testdata/03-multierror",
+			expectedFile: "expected_overrides.yaml",
+		},
+		{
+			testName: "no errors",
+			testData: "testdata/01-intern-new",
+		},
+	}
+
+	workingDir := getWorkingDir(t)
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			defer func() {
+				require.NoError(t, os.Chdir(workingDir))
+			}()
+
+			require.NoError(t, os.Chdir(testCase.testData))
+
+			suggestOverrides := filepath.Join(t.TempDir(), "overrides.yaml")
+			actErr := main.Main(&main.CLIArgs{
+				OutputFormat:     "txt",
+				GoTarFilename:    filepath.Join("..", "go1.17.3-testdata.src.tar.gz"),
+				Package:          "mod",
+				OutputType:       "markdown",
+				ApplicationType:  "external",
+				SuggestOverrides: suggestOverrides,
+			})
+
+			if testCase.expectedFile == "" {
+				require.NoError(t, actErr)
+				assert.NoFileExists(t, suggestOverrides)
+				return
+			}
+
+			// The stub is only a suggestion; the errors are still returned
+			require.Error(t, actErr)
+			assert.Equal(t, string(getFileContents(t, "expected_err.txt")), actErr.Error())
+
+			stub, err := os.ReadFile(suggestOverrides)
+			require.NoError(t, err)
+			assert.Equal(t, string(getFileContents(t, testCase.expectedFile)), string(stub))
+		})
+	}
+}
+
+func TestLicenseDatabase(t *testing.T) {
+	workingDir := getWorkingDir(t)
+	defer func() {
+		require.NoError(t, os.Chdir(workingDir))
+	}()
+	require.NoError(t, os.Chdir("testdata/00-intern-old"))
+
+	database := filepath.Join(t.TempDir(), "database.yaml")
+	require.NoError(t, os.WriteFile(database, []byte(""+
+		"version: 1\n"+
+		"go:\n"+
+		"  github.com/josharian/intern@<v1.0.1:\n"+
+		"    licenses: [MIT]\n"+
+		"    reason: License had a funny filename\n"), 0o644))
+	output := filepath.Join(t.TempDir(), "DEPENDENCIES.md")
+
+	err := main.Main(&main.CLIArgs{
+		OutputFormat:     "txt",
+		Output:           output,
+		GoTarFilename:    filepath.Join("..", "go1.17.3-testdata.src.tar.gz"),
+		Package:          "mod",
+		OutputType:       "markdown",
+		ApplicationType:  "external",
+		LicenseDatabases: []string{database},
+	})
+	require.NoError(t, err)
+	assert.Regexp(t, `github.com/josharian/intern +v1.0.0 +MIT license`, string(getFileContents(t, output)))
+}
+
+func TestCache(t *testing.T) {
+	workingDir := getWorkingDir(t)
+	defer func() {
+		require.NoError(t, os.Chdir(workingDir))
+	}()
+	require.NoError(t, os.Chdir("testdata/01-intern-new"))
+
+	cacheDir := t.TempDir()
+	var outputs []string
+	for i := 0; i < 2; i++ {
+		output := filepath.Join(t.TempDir(), "dependencies.json")
+		err := main.Main(&main.CLIArgs{
+			OutputFormat:    "txt",
+			Output:          output,
+			GoTarFilename:   filepath.Join("..", "go1.17.3-testdata.src.tar.gz"),
+			Package:         "mod",
+			OutputType:      "json",
+			ApplicationType: "external",
+			CacheDir:        cacheDir,
+			Verbose:         true,
+		})
+		require.NoError(t, err)
+		outputs = append(outputs, string(getFileContents(t, output)))
+
+		// The second scan uses the cache entries of the first: one for
+		// the stdlib and one for github.com/josharian/intern
+		entries, err := filepath.Glob(filepath.Join(cacheDir, "*", "*.json"))
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+	}
+	assert.Equal(t, *getDependencyInfoFromFile(t, "expected_verbose_json_output.json"), *getDependencyInfoFromReader(t, strings.NewReader(outputs[0])))
+	assert.Equal(t, outputs[0], outputs[1])
+}
+
+func TestJobs(t *testing.T) {
+	workingDir := getWorkingDir(t)
+	defer func() {
+		require.NoError(t, os.Chdir(workingDir))
+	}()
+	require.NoError(t, os.Chdir("testdata/03-multierror"))
+
+	// The errors are in the same order however many packages are
+	// detected at once.
+	for _, jobs := range []int{1, 2, 16} {
+		err := main.Main(&main.CLIArgs{
+			OutputFormat:    "txt",
+			GoTarFilename:   filepath.Join("..", "go1.17.3-testdata.src.tar.gz"),
+			Package:         "mod",
+			OutputType:      "markdown",
+			ApplicationType: "external",
+			NoCache:         true,
+			Jobs:            jobs,
+		})
+		require.Error(t, err)
+		assert.Equal(t, string(getFileContents(t, "expected_err.txt")), err.Error(), "--jobs=%d", jobs)
+	}
+}
+
+func TestReview(t *testing.T) {
+	workingDir := getWorkingDir(t)
+	defer func() {
+		require.NoError(t, os.Chdir(workingDir))
+	}()
+	require.NoError(t, os.Chdir("testdata/03-multierror"))
+
+	unparsablePackages := filepath.Join(t.TempDir(), "unparsable-packages.yaml")
+	args := &main.ReviewArgs{
+		GoTarFilename:      filepath.Join("..", "go1.17.3-testdata.src.tar.gz"),
+		Package:            "mod",
+		UnparsablePackages: unparsablePackages,
+	}
+
+	// Skip example.com/gpl, and give github.com/josharian/intern an
+	// invalid license before settling on the suggested one.
+	var out bytes.Buffer
+	input := strings.Join([]string{
+		"s",
+		"Frobnicate-1.0",
+		"",
+		"",
+		"license.md is the MIT license",
+	}, "\n") + "\n"
+	require.NoError(t, main.Review(args, strings.NewReader(input), &out))
+	assert.Contains(t, out.String(), `[1/2] example.com/gpl`)
+	assert.Contains(t, out.String(), `"GPL-3.0-or-later-with-some-non-standard-exception" is a variation of "GPL-3.0-or-later"`)
+	assert.Contains(t, out.String(), `github.com/josharian/intern/license.md is 98% the same as the MIT license (MIT):`)
+	assert.Contains(t, out.String(), `"Frobnicate-1.0" is not a valid SPDX License identifier`)
+	assert.Contains(t, out.String(), `Reviewed 1 of 2 packages.`)
+	assert.Equal(t, ""+
+		"# v1.0.0: license.md is the MIT license\n"+
+		"github.com/josharian/intern:\n"+
+		"  - MIT\n",
+		string(getFileContents(t, unparsablePackages)))
+
+	// Packages that have been reviewed aren't asked about again
+	out.Reset()
+	require.NoError(t, main.Review(args, strings.NewReader("q\n"), &out))
+	assert.Contains(t, out.String(), `[1/1] example.com/gpl`)
+	assert.Contains(t, out.String(), `Reviewed 0 of 1 packages.`)
+
+	// ...and the file that review saves is used by a regular run
+	actErr := main.Main(&main.CLIArgs{
+		OutputFormat:       "txt",
+		GoTarFilename:      args.GoTarFilename,
+		Package:            "mod",
+		OutputType:         "markdown",
+		ApplicationType:    "external",
+		UnparsablePackages: unparsablePackages,
+	})
+	require.Error(t, actErr)
+	assert.NotContains(t, actErr.Error(), "github.com/josharian/intern")
+}
+
+func TestReviewHelp(t *testing.T) {
+	originalStdOut, r, w := interceptStdOut()
+	originalStdErr := os.Stderr
+	os.Stderr = w
+	defer func() {
+		os.Stdout, os.Stderr = originalStdOut, originalStdErr
+	}()
+
+	// --help doesn't complain about the missing --unparsable-packages.
+	_, err := main.ParseReviewArgs([]string{"--help"})
+	_ = w.Close()
+	assert.ErrorIs(t, err, pflag.ErrHelp)
+
+	usage, readErr := io.ReadAll(r)
+	require.NoError(t, readErr)
+	assert.Contains(t, string(usage), " review OPTIONS\n")
+	assert.Contains(t, string(usage), "--unparsable-packages")
+}
+
 func TestErrorScenarios(t *testing.T) {
 	testCases := []struct {
 		testName                string
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/pflag"
+
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+	"github.com/datawire/go-mkopensource/pkg/mkopensource"
+)
+
+const (
+	// How much of each offending file `review` shows.
+	reviewMaxFiles     = 5
+	reviewMaxFileLines = 20
+)
+
+// ReviewArgs are the arguments of `go-mkopensource review`.
+type ReviewArgs struct {
+	GoTarFilename       string
+	GoVersion           string
+	GoLicenseFile       string
+	Package             string
+	UnparsablePackages  string
+	ProprietarySoftware string
+	VerifySources       string
+	Profile             string
+	LicenseDatabases    []string
+}
+
+// ParseReviewArgs parses the arguments of `go-mkopensource review`
+// (argv, without "review").  For --help it prints the usage, and
+// returns pflag.ErrHelp.
+func ParseReviewArgs(argv []string) (*ReviewArgs, error) {
+	args := &ReviewArgs{}
+	argparser := pflag.NewFlagSet(os.Args[0]+" review", pflag.ContinueOnError)
+	help := false
+	argparser.BoolVarP(&help, "help", "h", false, "Show this message")
+	argparser.StringVar(&args.GoTarFilename, "gotar", "",
+		"Tarball of the Go stdlib source code to read the version and license of the stdlib from (default: the local Go toolchain)")
+	argparser.StringVar(&args.GoVersion, "go-version", "",
+		"Version of the Go stdlib, instead of that of the local Go toolchain or the go.mod toolchain directive. Only valid without --gotar")
+	argparser.StringVar(&args.GoLicenseFile, "go-license", "",
+		"LICENSE file of the Go stdlib, instead of that of the local Go toolchain. Only valid without --gotar")
+	argparser.StringVar(&args.Package, "package", "", "The package(s) to review the licenses of")
+	argparser.StringVar(&args.UnparsablePackages, "unparsable-packages", "",
+		"Yaml file containing SPDX License IDs for packages that have valid licenses, but cannot be parsed by this license checker; the licenses that you assign are saved to it")
+	argparser.StringVar(&args.ProprietarySoftware, "proprietary-software", "", "Yaml file containing proprietary packages")
+	argparser.StringVar(&args.Profile, "profile", detectlicense.DefaultProfile,
+		fmt.Sprintf("The organization that the license policy is of: one of %s, or a yaml file", strings.Join(detectlicense.ShippedProfiles(), ", ")))
+	argparser.StringArrayVar(&args.LicenseDatabases, "license-database", nil,
+		"Yaml or json license database to layer on top of the built-in one; may be given more than once")
+	argparser.StringVar(&args.VerifySources, "verify-sources", mkopensource.VerifyError,
+		fmt.Sprintf("What to do if the files of a module don't match go.sum. One of: %s, %s, %s", mkopensource.VerifyError, mkopensource.VerifyWarn, mkopensource.VerifyOff))
+
+	if err := argparser.Parse(argv); err != nil {
+		return nil, err
+	}
+	if help {
+		fmt.Printf("Usage: %v review OPTIONS\n", os.Args[0])
+		fmt.Println("Interactively assign licenses to the packages whose license can't be detected")
+		fmt.Println()
+		fmt.Println("OPTIONS:")
+		argparser.PrintDefaults()
+		return nil, pflag.ErrHelp
+	}
+	if argparser.NArg() != 0 {
+		return nil, fmt.Errorf("expected 0 arguments, got %d: %q", argparser.NArg(), argparser.Args())
+	}
+
+	switch args.VerifySources {
+	case mkopensource.VerifyError, mkopensource.VerifyWarn, mkopensource.VerifyOff:
+	default:
+		return nil, fmt.Errorf("--verify-sources must be one of '%s', '%s', '%s'", mkopensource.VerifyError, mkopensource.VerifyWarn, mkopensource.VerifyOff)
+	}
+	if args.UnparsablePackages == "" {
+		return nil, errors.New("--unparsable-packages must be non-empty")
+	}
+	if args.GoTarFilename != "" {
+		if !strings.HasPrefix(filepath.Base(args.GoTarFilename), "go1.") || !strings.HasSuffix(args.GoTarFilename, ".tar.gz") {
+			return nil, fmt.Errorf("--gotar (%q) doesn't look like a go1.*.tar.gz file", args.GoTarFilename)
+		}
+		if args.GoVersion != "" || args.GoLicenseFile != "" {
+			return nil, errors.New("--go-version and --go-license are only valid without --gotar")
+		}
+	}
+	if args.Package == "" {
+		return nil, fmt.Errorf("--package (%q) must be non-empty", args.Package)
+	}
+
+	return args, nil
+}
+
+func reviewMain() {
+	args, err := ParseReviewArgs(os.Args[2:])
+	if err != nil {
+		if err == pflag.ErrHelp {
+			os.Exit(int(NoError))
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "%s review: %v\nTry '%s review --help' for more information.\n", os.Args[0], err, os.Args[0])
+		os.Exit(int(InvalidArgumentsError))
+	}
+	if err := Review(args, os.Stdin, os.Stdout); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s review: fatal: %v\n", os.Args[0], err)
+		os.Exit(int(DependencyGenerationError))
+	}
+}
+
+// Review walks a human through each package whose license can't be
+// detected (and that doesn't already have an --unparsable-packages
+// entry): it shows them the offending files and the nearest known
+// license, reads the SPDX identifiers that they assign and why from
+// in, and saves them in args.UnparsablePackages.
+func Review(args *ReviewArgs, in io.Reader, out io.Writer) error {
+	profile, err := detectlicense.LoadProfile(args.Profile)
+	if err != nil {
+		return err
+	}
+	database, err := detectlicense.LoadDatabase(args.LicenseDatabases...)
+	if err != nil {
+		return err
+	}
+	proprietarySoftware := profile.Proprietary()
+	if args.ProprietarySoftware != "" {
+		if err := proprietarySoftware.ReadProprietarySoftwareFile(args.ProprietarySoftware); err != nil {
+			return err
+		}
+	}
+	scanner, err := mkopensource.NewScanner(mkopensource.Options{
+		Package:             args.Package,
+		GoTarFilename:       args.GoTarFilename,
+		GoVersion:           args.GoVersion,
+		GoLicenseFile:       args.GoLicenseFile,
+		UnparsablePackages:  args.UnparsablePackages,
+		Database:            database,
+		Profile:             profile,
+		ProprietarySoftware: proprietarySoftware,
+		VerifySources:       args.VerifySources,
+	})
+	if err != nil {
+		return err
+	}
+	result, err := scanner.Scan()
+	if err != nil {
+		return err
+	}
+	for _, warning := range result.Warnings {
+		_, _ = fmt.Fprintf(os.Stderr, "WARNING: %v\n", warning)
+	}
+
+	// Packages that already have an --unparsable-packages entry don't
+	// have errors, so only the ones that still need a human are
+	// suggested.
+	suggestions := suggestOverrides(result.Errors, result.PackageFiles)
+	if len(suggestions) == 0 {
+		_, err := fmt.Fprintln(out, "There are no packages whose license can't be detected.")
+		return err
+	}
+
+	input := bufio.NewScanner(in)
+	saved := 0
+	for i, suggestion := range suggestions {
+		fmt.Fprintf(out, "\n[%d/%d] %s %s\n%s\n", i+1, len(suggestions), suggestion.Name, suggestion.Version, suggestion.Reason)
+		showReviewFiles(out, suggestion, result.PackageFiles[suggestion.Name])
+
+		ids, ok := promptLicenses(input, out, suggestion.SPDXIDs)
+		if !ok {
+			break
+		}
+		if ids == nil {
+			continue
+		}
+		justification, ok := prompt(input, out, "Justification: ")
+		for ok && justification == "" {
+			justification, ok = prompt(input, out, "A justification is required: ")
+		}
+		if !ok {
+			break
+		}
+
+		comment := fmt.Sprintf("%s: %s", suggestion.Version, justification)
+		if err := detectlicense.AddPackageLicensesToFile(args.UnparsablePackages, suggestion.Name, ids, comment); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Saved %s: %s to %s\n", suggestion.Name, strings.Join(ids, ", "), args.UnparsablePackages)
+		saved++
+	}
+
+	_, err = fmt.Fprintf(out, "\nReviewed %d of %d packages.\n", saved, len(suggestions))
+	return err
+}
+
+// showReviewFiles shows the files that a license couldn't be detected
+// in: as a diff against the nearest known license if there is one, or
+// else the start of the file.
+func showReviewFiles(out io.Writer, suggestion overrideSuggestion, files map[string][]byte) {
+	for i, filename := range suggestion.Files {
+		if i == reviewMaxFiles {
+			fmt.Fprintf(out, "\n... and %d more files\n", len(suggestion.Files)-reviewMaxFiles)
+			break
+		}
+		body := files[filename]
+		if guess, ok := detectlicense.GuessLicense(body); ok {
+			canonical, _ := detectlicense.CanonicalText(guess.License)
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(string(canonical)),
+				B:        difflib.SplitLines(string(body)),
+				FromFile: guess.SPDXID,
+				ToFile:   filename,
+				Context:  1,
+			})
+			if err == nil {
+				fmt.Fprintf(out, "\n%s is %.0f%% the same as the %s (%s):\n%s",
+					filename, guess.Similarity*100, guess.License.Name, guess.SPDXID, diff)
+				continue
+			}
+		}
+		lines := strings.SplitAfter(string(body), "\n")
+		fmt.Fprintf(out, "\n%s:\n", filename)
+		if len(lines) > reviewMaxFileLines {
+			fmt.Fprintf(out, "%s... (%d more lines)\n", strings.Join(lines[:reviewMaxFileLines], ""), len(lines)-reviewMaxFileLines)
+		} else {
+			fmt.Fprint(out, strings.Join(lines, ""))
+		}
+	}
+	for _, why := range suggestion.Why {
+		fmt.Fprintf(out, "\nBest guess: %s\n", why)
+	}
+}
+
+// promptLicenses asks for the SPDX identifiers of a package, until it
+// gets valid ones.  It returns nil if the package is skipped, and false
+// if the human wants to stop.
+func promptLicenses(input *bufio.Scanner, out io.Writer, guesses []string) ([]string, bool) {
+	question := `SPDX identifier(s), separated by spaces ("s" to skip, "q" to quit): `
+	if len(guesses) > 0 {
+		question = fmt.Sprintf(`SPDX identifier(s), separated by spaces [%s] ("s" to skip, "q" to quit): `, strings.Join(guesses, " "))
+	}
+	for {
+		answer, ok := prompt(input, out, question)
+		switch {
+		case !ok || answer == "q":
+			return nil, false
+		case answer == "s":
+			return nil, true
+		case answer == "" && len(guesses) > 0:
+			return guesses, true
+		case answer == "":
+			continue
+		}
+		ids := strings.Fields(answer)
+		valid := true
+		for _, id := range ids {
+			if _, ok := detectlicense.SpdxIdentifiers[id]; !ok {
+				fmt.Fprintf(out, "%q is not a valid SPDX License identifier. See https://spdx.org/licenses/ for a full list\n", id)
+				valid = false
+			}
+		}
+		if valid {
+			return ids, true
+		}
+	}
+}
+
+// prompt asks a question, and returns the answer; it returns false if
+// there is no more input.
+func prompt(input *bufio.Scanner, out io.Writer, question string) (string, bool) {
+	fmt.Fprint(out, question)
+	if !input.Scan() {
+		fmt.Fprintln(out)
+		return "", false
+	}
+	return strings.TrimSpace(input.Text()), true
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
+)
+
+// unknownLicense is what goes in the stub for a package that we have
+// no guess for.  It isn't a valid SPDX identifier, so that the stub
+// can't be used as an --unparsable-packages file until a human has
+// filled it in.
+const unknownLicense = "FIXME"
+
+// overrideSuggestion is a stub --unparsable-packages entry for a
+// package whose license couldn't be detected.
+type overrideSuggestion struct {
+	Name    string
+	Version string
+	Reason  string
+	Files   []string
+	// CurationKeys are the detectlicense.CurationKey of each of the
+	// Files that is a license file.
+	CurationKeys map[string]string
+	// SPDXIDs are the best guesses at the package's licenses, and
+	// Why says, for each of them, what the guess is based on.
+	SPDXIDs []string
+	Why     []string
+}
+
+// suggestOverrides returns a suggestion for each of the license
+// detection errors that an --unparsable-packages entry could fix.
+func suggestOverrides(licErrs []error, pkgFiles map[string]map[string][]byte) []overrideSuggestion {
+	var suggestions []overrideSuggestion
+	for _, err := range licErrs {
+		var detection *scanningerrors.DetectionError
+		var spdx *scanningerrors.UnknownSPDXError
+		switch {
+		case errors.As(err, &detection):
+			suggestion := overrideSuggestion{
+				Name:    detection.Name,
+				Version: detection.Version,
+				Reason:  detection.Reason,
+				Files:   detection.Files,
+			}
+			for _, filename := range detection.Files {
+				if detectlicense.IsLicenseFile(filename) {
+					if suggestion.CurationKeys == nil {
+						suggestion.CurationKeys = make(map[string]string)
+					}
+					suggestion.CurationKeys[filename] = detectlicense.CurationKey(pkgFiles[detection.Name][filename])
+				}
+				guess, ok := detectlicense.GuessLicense(pkgFiles[detection.Name][filename])
+				if !ok {
+					continue
+				}
+				if !slices.Contains(suggestion.SPDXIDs, guess.SPDXID) {
+					suggestion.SPDXIDs = append(suggestion.SPDXIDs, guess.SPDXID)
+				}
+				suggestion.Why = append(suggestion.Why,
+					fmt.Sprintf("%s is %.0f%% the same as the %s", filename, guess.Similarity*100, guess.License.Name))
+			}
+			suggestions = append(suggestions, suggestion)
+		case errors.As(err, &spdx):
+			suggestion := overrideSuggestion{
+				Name:    spdx.Name,
+				Version: spdx.Version,
+				Reason:  spdx.Error(),
+			}
+			if spdx.File != "" {
+				suggestion.Files = []string{spdx.File}
+			}
+			if id, ok := detectlicense.GuessSPDXIdentifier(spdx.ID); ok {
+				suggestion.SPDXIDs = []string{id}
+				suggestion.Why = []string{fmt.Sprintf("%q is a variation of %q", spdx.ID, id)}
+			}
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Name < suggestions[j].Name
+	})
+	return suggestions
+}
+
+// writeOverridesStub writes the suggestions as a commented
+// --unparsable-packages file, for a human to review.
+func writeOverridesStub(w io.Writer, suggestions []overrideSuggestion) error {
+	var out strings.Builder
+	out.WriteString("" +
+		"# Suggested --unparsable-packages entries for the packages whose\n" +
+		"# license could not be detected.\n" +
+		"#\n" +
+		"# The licenses below are GUESSES, and go-mkopensource never uses\n" +
+		"# this file by itself.  Check the license of each package yourself\n" +
+		"# (see \"When scanning fails\" in the README), fix any entries that\n" +
+		"# are wrong or FIXME, and only then copy them in to your\n" +
+		"# --unparsable-packages file.  If the same license file is used by\n" +
+		"# other packages, add it to the curations of a --license-database\n" +
+		"# instead, with its curation key.\n")
+	for _, suggestion := range suggestions {
+		out.WriteString("\n")
+		fmt.Fprintf(&out, "# %s %s\n", suggestion.Name, suggestion.Version)
+		fmt.Fprintf(&out, "#   %s\n", suggestion.Reason)
+		if len(suggestion.Files) > 0 {
+			out.WriteString("#   files:\n")
+			for _, filename := range suggestion.Files {
+				if key, ok := suggestion.CurationKeys[filename]; ok {
+					fmt.Fprintf(&out, "#     %s (curation key %s)\n", filename, key)
+				} else {
+					fmt.Fprintf(&out, "#     %s\n", filename)
+				}
+			}
+		}
+		if len(suggestion.SPDXIDs) == 0 {
+			out.WriteString("#   no guess; look up the license(s) by hand\n")
+		}
+		for _, why := range suggestion.Why {
+			fmt.Fprintf(&out, "#   best guess: %s\n", why)
+		}
+		fmt.Fprintf(&out, "%s:\n", suggestion.Name)
+		ids := suggestion.SPDXIDs
+		if len(ids) == 0 {
+			ids = []string{unknownLicense}
+		}
+		for _, id := range ids {
+			fmt.Fprintf(&out, "  - %s\n", id)
+		}
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
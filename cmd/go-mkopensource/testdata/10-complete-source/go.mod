@@ -0,0 +1,10 @@
+module testmod
+
+go 1.17
+
+require (
+	example.com/mpllib v0.0.0-00010101000000-000000000000
+	github.com/hashicorp/errwrap v1.1.0
+)
+
+replace example.com/mpllib => ./mpllib
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"example.com/mpllib"
+	"github.com/hashicorp/errwrap"
+)
+
+func main() {
+	err := errwrap.Wrapf("wrapped: {{err}}", errors.New(mpllib.Greeting()))
+	fmt.Println(err)
+}
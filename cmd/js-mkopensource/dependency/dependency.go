@@ -7,6 +7,7 @@ import (
 	"github.com/datawire/go-mkopensource/pkg/detectlicense"
 	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
 	"io"
+	"os"
 	"regexp"
 	"sort"
 	"strings"
@@ -36,7 +37,11 @@ func (n *nodeDependency) licenses() (string, error) {
 		for _, v := range licenseArray {
 			license, ok := v.(string)
 			if !ok {
-				return "", fmt.Errorf("Dependency '%s@%s' has an invalid license field: %#v", n.Name, n.Version, n.Licenses)
+				return "", &scanningerrors.DetectionError{
+					Name:    n.Name,
+					Version: n.Version,
+					Reason:  fmt.Sprintf("Dependency '%s@%s' has an invalid license field: %#v", n.Name, n.Version, n.Licenses),
+				}
 			}
 			licenses = append(licenses, license)
 		}
@@ -44,10 +49,31 @@ func (n *nodeDependency) licenses() (string, error) {
 		return strings.Join(licenses, " AND "), nil
 	}
 
-	return "", fmt.Errorf("Dependency '%s@%s' has an invalid license field: %v", n.Name, n.Version, n.Licenses)
+	return "", &scanningerrors.DetectionError{
+		Name:    n.Name,
+		Version: n.Version,
+		Reason:  fmt.Sprintf("Dependency '%s@%s' has an invalid license field: %v", n.Name, n.Version, n.Licenses),
+	}
+}
+
+// licenseFile returns the contents of the dependency's license file,
+// as license-checker reported it or else as it is on disk.
+func (n *nodeDependency) licenseFile() []byte {
+	if n.LicenseText != "" || n.LicenseFile == "" {
+		return []byte(n.LicenseText)
+	}
+	body, err := os.ReadFile(n.LicenseFile)
+	if err != nil {
+		return nil
+	}
+	return body
 }
 
-func GetDependencyInformation(r io.Reader, licenseRestriction detectlicense.LicenseRestriction) (dependencyInfo dependencies.DependencyInfo, err error) {
+// GetDependencyInformation reads the output of license-checker, and
+// returns the dependencies in it, leaving out the ones that are
+// proprietarySoftware (which may be nil).
+func GetDependencyInformation(r io.Reader, licenseRestriction detectlicense.LicenseRestriction,
+	proprietarySoftware detectlicense.ProprietarySoftware) (dependencyInfo dependencies.DependencyInfo, err error) {
 	nodeDependencies := &NodeDependencies{}
 	data, err := io.ReadAll(r)
 	if err != nil {
@@ -67,6 +93,9 @@ func GetDependencyInformation(r io.Reader, licenseRestriction detectlicense.Lice
 loop:
 	for _, dependencyId := range sortedDependencies {
 		nodeDependency := (*nodeDependencies)[dependencyId]
+		if name, _ := splitDependencyIdentifier(dependencyId); proprietarySoftware.IsProprietarySoftware(name) {
+			continue
+		}
 
 		dependency, dependencyErr := getDependencyDetails(nodeDependency, dependencyId)
 		if dependencyErr != nil {
@@ -120,7 +149,11 @@ func getDependencyLicenses(dependencyId string, nodeDependency nodeDependency) (
 	}
 
 	if licenseString == "" {
-		return nil, fmt.Errorf("Dependency '%s@%s' is missing a license identifier.", nodeDependency.Name, nodeDependency.Version)
+		return nil, &scanningerrors.DetectionError{
+			Name:    nodeDependency.Name,
+			Version: nodeDependency.Version,
+			Reason:  fmt.Sprintf("Dependency '%s@%s' is missing a license identifier.", nodeDependency.Name, nodeDependency.Version),
+		}
 	}
 
 	parenthesisRe, err := regexp.Compile(`^\(|\)$`)
@@ -143,14 +176,29 @@ func getDependencyLicenses(dependencyId string, nodeDependency nodeDependency) (
 			continue
 		}
 
-		licenses, ok := hardcodedJsDependencies[dependencyId]
-		if ok {
-			allLicenses = licenses
+		if override, ok := detectlicense.CurrentDatabase().Npm.Lookup(splitDependencyIdentifier(dependencyId)); ok && override.Covers(nodeDependency.licenseFile()) {
+			allLicenses = []string{}
+			for _, license := range override.Licenses {
+				allLicenses = append(allLicenses, license.Name)
+			}
 			break
 		}
 
-		return nil, fmt.Errorf("Dependency '%s@%s' has an unknown SPDX Identifier '%s'.",
-			nodeDependency.Name, nodeDependency.Version, spdxId)
+		if licenseFile := nodeDependency.licenseFile(); len(licenseFile) > 0 {
+			if curated, ok := detectlicense.IdentifyCuratedLicenses(licenseFile); ok {
+				allLicenses = []string{}
+				for license := range curated {
+					allLicenses = append(allLicenses, license.Name)
+				}
+				break
+			}
+		}
+
+		return nil, &scanningerrors.UnknownSPDXError{
+			Name:    nodeDependency.Name,
+			Version: nodeDependency.Version,
+			ID:      spdxId,
+		}
 	}
 
 	sort.Strings(allLicenses)
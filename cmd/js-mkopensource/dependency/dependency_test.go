@@ -1,17 +1,31 @@
 package dependency_test
 
 import (
+	"errors"
 	"github.com/datawire/go-mkopensource/cmd/js-mkopensource/dependency"
 	"github.com/datawire/go-mkopensource/pkg/dependencies"
 	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"io"
 	"os"
 	"path"
+	"sort"
 	"testing"
 )
 
+func TestMain(m *testing.M) {
+	// The expected errors are what js-mkopensource says with its
+	// default profile.
+	profile, err := detectlicense.LoadProfile(detectlicense.DefaultProfile)
+	if err != nil {
+		panic(err)
+	}
+	profile.Apply()
+	os.Exit(m.Run())
+}
+
 func TestSuccessfulGeneration(t *testing.T) {
 	testCases := []struct {
 		testName string
@@ -33,6 +47,10 @@ func TestSuccessfulGeneration(t *testing.T) {
 			"Hardcoded dependencies are properly parsed",
 			"./testdata/hardcoded-dependencies",
 		},
+		{
+			"Hardcoded dependencies can be a range of versions",
+			"./testdata/hardcoded-dependencies-version-range",
+		},
 		{
 			"GPL license is allowed in internal software",
 			"./testdata/dependency-with-gpl-license",
@@ -50,7 +68,7 @@ func TestSuccessfulGeneration(t *testing.T) {
 			defer func() { _ = nodeDependencies.Close() }()
 
 			// Act
-			dependencyInformation, err := dependency.GetDependencyInformation(nodeDependencies, detectlicense.AmbassadorServers)
+			dependencyInformation, err := dependency.GetDependencyInformation(nodeDependencies, detectlicense.AmbassadorServers, nil)
 			require.NoError(t, err)
 
 			// Assert
@@ -93,6 +111,10 @@ func TestErrorScenarios(t *testing.T) {
 			"AGPL license is forbidden",
 			"./testdata/dependency-with-agpl-license",
 		},
+		{
+			"Source-available license is forbidden",
+			"./testdata/dependency-with-source-available-license",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -102,7 +124,7 @@ func TestErrorScenarios(t *testing.T) {
 			defer func() { _ = nodeDependencies.Close() }()
 
 			// Act
-			_, err := dependency.GetDependencyInformation(nodeDependencies, detectlicense.Unrestricted)
+			_, err := dependency.GetDependencyInformation(nodeDependencies, detectlicense.Unrestricted, nil)
 
 			// Assert
 			require.Error(t, err)
@@ -112,6 +134,82 @@ func TestErrorScenarios(t *testing.T) {
 	}
 }
 
+func TestCuratedLicense(t *testing.T) {
+	database, err := detectlicense.LoadDatabase("./testdata/curated-license/database.yaml")
+	require.NoError(t, err)
+	detectlicense.SetDatabase(database)
+	defer detectlicense.SetDatabase(nil)
+
+	nodeDependencies := getNodeDependencies(t, path.Join("./testdata/curated-license", "dependencies.json"))
+	defer func() { _ = nodeDependencies.Close() }()
+
+	dependencyInformation, err := dependency.GetDependencyInformation(nodeDependencies, detectlicense.AmbassadorServers, nil)
+	require.NoError(t, err)
+
+	expectedJson := getDependencyInfoFromFile(t, path.Join("./testdata/curated-license", "expected_output.json"))
+	require.Equal(t, *expectedJson, dependencyInformation)
+}
+
+func TestProprietarySoftware(t *testing.T) {
+	nodeDependencies := getNodeDependencies(t, path.Join("./testdata/proprietary-scope", "dependencies.json"))
+	defer func() { _ = nodeDependencies.Close() }()
+
+	proprietarySoftware := detectlicense.GetAmbassadorProprietarySoftware("@ourorg/*")
+	dependencyInformation, err := dependency.GetDependencyInformation(nodeDependencies, detectlicense.Unrestricted, proprietarySoftware)
+	require.NoError(t, err)
+
+	expectedJson := getDependencyInfoFromFile(t, path.Join("./testdata/proprietary-scope", "expected_output.json"))
+	require.Equal(t, *expectedJson, dependencyInformation)
+}
+
+func TestTypedErrors(t *testing.T) {
+	nodeDependencies := getNodeDependencies(t, path.Join("./testdata/unknown-license", "dependencies.json"))
+	defer func() { _ = nodeDependencies.Close() }()
+
+	_, err := dependency.GetDependencyInformation(nodeDependencies, detectlicense.Unrestricted, nil)
+	require.Error(t, err)
+
+	errs := scanningerrors.Errors(err)
+	require.Len(t, errs, 1)
+	var spdxErr *scanningerrors.UnknownSPDXError
+	require.True(t, errors.As(errs[0], &spdxErr))
+	assert.Equal(t, &scanningerrors.UnknownSPDXError{
+		Name:    "agent-base",
+		Version: "6.0.2",
+		ID:      "UNKNOWN",
+	}, spdxErr)
+}
+
+func TestGetCompleteSource(t *testing.T) {
+	//Arrange
+	input := "./testdata/weak-copyleft-source"
+	nodeDependencies := getNodeDependencies(t, path.Join(input, "dependencies.json"))
+	defer func() { _ = nodeDependencies.Close() }()
+
+	// Act
+	files, manifest, err := dependency.GetCompleteSource(nodeDependencies)
+	require.NoError(t, err)
+
+	// Assert: only the MPL package is included, all of it, but not
+	// the packages nested inside of it.
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	assert.Equal(t, []string{
+		"mpl-lib@2.1.0/LICENSE",
+		"mpl-lib@2.1.0/index.js",
+		"mpl-lib@2.1.0/lib/util.js",
+		"mpl-lib@2.1.0/package.json",
+	}, filenames)
+
+	manifestJSON, err := manifest.Marshal()
+	require.NoError(t, err)
+	expectedManifest := getFileContents(t, path.Join(input, "expected_sources.json"))
+	assert.Equal(t, string(expectedManifest), string(manifestJSON))
+}
+
 func getNodeDependencies(t *testing.T, dependencyFile string) *os.File {
 	nodeDependencies, openErr := os.Open(dependencyFile)
 	require.NoError(t, openErr)
@@ -0,0 +1,142 @@
+package dependency
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/datawire/go-mkopensource/pkg/archive"
+	"github.com/datawire/go-mkopensource/pkg/dependencies"
+)
+
+// packageJSON is the part of an installed package's package.json that
+// we care about.  npm records where it installed the package from in
+// the "_"-prefixed fields.
+type packageJSON struct {
+	Integrity string `json:"_integrity"`
+	Resolved  string `json:"_resolved"`
+}
+
+// GetCompleteSource takes the same license-checker output as
+// GetDependencyInformation, and returns the complete source code of
+// each of the weak-copyleft packages in it (licenses like the LGPL and
+// MPL require that it be made available), read from the package's
+// directory in node_modules.  The files are named
+// "<name>@<version>/<filename>".  The manifest describes the files and
+// where they came from.
+func GetCompleteSource(r io.Reader) (files map[string][]byte, manifest archive.Manifest, err error) {
+	nodeDependencies := &NodeDependencies{}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, manifest, err
+	}
+	if err := json.Unmarshal(data, nodeDependencies); err != nil {
+		return nil, manifest, err
+	}
+
+	files = make(map[string][]byte)
+	for _, dependencyId := range getSortedDependencies(nodeDependencies) {
+		nodeDependency := (*nodeDependencies)[dependencyId]
+
+		dependency, err := getDependencyDetails(nodeDependency, dependencyId)
+		if err != nil {
+			return nil, manifest, err
+		}
+		weakCopyleft := false
+		for _, licenseName := range dependency.Licenses {
+			license, err := dependencies.GetLicenseFromName(licenseName)
+			if err != nil {
+				return nil, manifest, err
+			}
+			weakCopyleft = weakCopyleft || license.WeakCopyleft
+		}
+		if !weakCopyleft {
+			continue
+		}
+
+		if nodeDependency.Path == "" {
+			return nil, manifest, fmt.Errorf("Dependency '%s@%s' is weak-copyleft, but its path is not known.",
+				dependency.Name, dependency.Version)
+		}
+		pkgFiles, err := collectPackageDir(nodeDependency.Path, dependency.Name+"@"+dependency.Version)
+		if err != nil {
+			return nil, manifest, err
+		}
+		for filename, body := range pkgFiles {
+			files[filename] = body
+		}
+
+		source := archive.PackageSource{
+			Name:    dependency.Name,
+			Version: dependency.Version,
+			Origin: archive.Origin{
+				Source:   "node_modules",
+				Location: nodeModulesLocation(nodeDependency.Path),
+				URL:      nodeDependency.Repository,
+			},
+			Files: archive.HashFiles(pkgFiles),
+		}
+		if body, ok := pkgFiles[path.Join(dependency.Name+"@"+dependency.Version, "package.json")]; ok {
+			var pkgJSON packageJSON
+			if err := json.Unmarshal(body, &pkgJSON); err == nil {
+				source.Sum = pkgJSON.Integrity
+				if pkgJSON.Resolved != "" {
+					source.Origin.Location = pkgJSON.Resolved
+				}
+			}
+		}
+		manifest.Packages = append(manifest.Packages, source)
+	}
+	return files, manifest, nil
+}
+
+// collectPackageDir reads all of the files of an installed package,
+// leaving out its own node_modules (those are separate packages) and
+// VCS metadata.
+func collectPackageDir(dir, dst string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			switch entry.Name() {
+			case "node_modules", ".git", ".hg", ".svn":
+				if filename != dir {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, filename)
+		if err != nil {
+			return err
+		}
+		body, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		files[path.Join(dst, filepath.ToSlash(rel))] = body
+		return nil
+	})
+	return files, err
+}
+
+// nodeModulesLocation trims a package's path down to the part starting
+// at "node_modules/", so that it doesn't depend on where the project
+// was checked out.
+func nodeModulesLocation(dir string) string {
+	dir = filepath.ToSlash(dir)
+	if idx := strings.Index(dir, "node_modules/"); idx >= 0 {
+		return dir[idx:]
+	}
+	return dir
+}
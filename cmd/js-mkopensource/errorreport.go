@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
+)
+
+const (
+	// Format of the report of scanning errors
+	textErrorsFormat  = "text"
+	jsonErrorsFormat  = "json"
+	sarifErrorsFormat = "sarif"
+)
+
+// packageJSONLocator returns a Locator that finds the line of a
+// package.json file that declares a dependency.
+func packageJSONLocator(filename string) (scanningerrors.Locator, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return func(dependency string) *scanningerrors.Location {
+		location := &scanningerrors.Location{File: filename}
+		re := regexp.MustCompile(`^\s*"` + regexp.QuoteMeta(dependency) + `"\s*:`)
+		for i, line := range lines {
+			if re.MatchString(line) {
+				location.Line = i + 1
+				break
+			}
+		}
+		return location
+	}, nil
+}
+
+// writeErrorReport writes the --errors-format report of the scanning
+// errors to the --errors-output file, or stdout.
+func writeErrorReport(args *CLIArgs, errs []error) error {
+	locate, err := packageJSONLocator("package.json")
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	report := scanningerrors.NewReport(errs, locate)
+
+	var body []byte
+	switch args.ErrorsFormat {
+	case jsonErrorsFormat:
+		body, err = report.JSON()
+	case sarifErrorsFormat:
+		body, err = report.SARIF("js-mkopensource")
+	default:
+		err = fmt.Errorf("unknown errors format %q", args.ErrorsFormat)
+	}
+	if err != nil {
+		return err
+	}
+
+	if args.ErrorsOutput == "" {
+		_, err := os.Stdout.Write(body)
+		return err
+	}
+	return os.WriteFile(args.ErrorsOutput, body, 0o644)
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/datawire/go-mkopensource/cmd/js-mkopensource/dependency"
+	"github.com/datawire/go-mkopensource/pkg/archive"
 	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
 	"github.com/spf13/pflag"
+	"io"
 	"os"
+	"strings"
 )
 
 const (
@@ -14,12 +19,19 @@ const (
 	// The only validation for "internal" is to check chat forbidden licenses are not used
 	internalApplication = "internal"
 	// "external" applications have additional license requirements as documented in
-	//https://www.notion.so/datawire/License-Management-5194ca50c9684ff4b301143806c92157
+	// the license policy of the --profile
 	externalApplication = "external"
 )
 
 type CLIArgs struct {
-	ApplicationType string
+	ApplicationType     string
+	SourceArchive       string
+	SourceArchiveName   string
+	ErrorsFormat        string
+	ErrorsOutput        string
+	ProprietarySoftware string
+	Profile             string
+	LicenseDatabases    []string
 }
 
 func main() {
@@ -34,12 +46,52 @@ func main() {
 
 	licenseRestriction := getLicenseRestriction(args.ApplicationType)
 
-	dependencyInfo, err := dependency.GetDependencyInformation(os.Stdin, licenseRestriction)
+	input, err := io.ReadAll(os.Stdin)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "%s: fatal: %v\n", os.Args[0], err)
 		os.Exit(int(DependencyGenerationError))
 	}
 
+	profile, err := detectlicense.LoadProfile(args.Profile)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: fatal: %v\n", os.Args[0], err)
+		os.Exit(int(DependencyGenerationError))
+	}
+	profile.Apply()
+	database, err := detectlicense.LoadDatabase(args.LicenseDatabases...)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: fatal: %v\n", os.Args[0], err)
+		os.Exit(int(DependencyGenerationError))
+	}
+	detectlicense.SetDatabase(database)
+
+	proprietarySoftware := profile.Proprietary()
+	if args.ProprietarySoftware != "" {
+		if err := proprietarySoftware.ReadProprietarySoftwareFile(args.ProprietarySoftware); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: fatal: %v\n", os.Args[0], err)
+			os.Exit(int(DependencyGenerationError))
+		}
+	}
+
+	dependencyInfo, err := dependency.GetDependencyInformation(bytes.NewReader(input), licenseRestriction, proprietarySoftware)
+	if err != nil {
+		if errs := scanningerrors.Errors(err); errs != nil && args.ErrorsFormat != textErrorsFormat {
+			if err := writeErrorReport(args, errs); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "%s: fatal: could not write error report: %v\n", os.Args[0], err)
+				os.Exit(int(WriteError))
+			}
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "%s: fatal: %v\n", os.Args[0], err)
+		os.Exit(int(DependencyGenerationError))
+	}
+
+	if args.SourceArchive != "" {
+		if err := writeSourceArchive(args, input); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: fatal: could not write %s: %v\n", os.Args[0], args.SourceArchive, err)
+			os.Exit(int(WriteError))
+		}
+	}
+
 	jsonString, marshalErr := json.Marshal(dependencyInfo)
 	if marshalErr != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "could not generate JSON output: %v\n", err)
@@ -61,8 +113,20 @@ func parseArgs() (*CLIArgs, error) {
 	argparser.BoolVarP(&help, "help", "h", false, "Show this message")
 	argparser.StringVar(&args.ApplicationType, "application-type", externalApplication,
 		fmt.Sprintf("Where will the application run. One of: %s, %s\n"+
-			"Internal applications are run on Ambassador servers.\n"+
+			"Internal applications are run on the organization's own servers.\n"+
 			"External applications run on customer machines", internalApplication, externalApplication))
+	argparser.StringVar(&args.SourceArchive, "source-archive", "",
+		fmt.Sprintf("Also write a .tar.gz file with the complete source of the weak-copyleft packages, and a %s manifest of it", archive.ManifestFilename))
+	argparser.StringVar(&args.SourceArchiveName, "source-archive-name", "",
+		"Name of the root directory in the --source-archive tarball")
+	argparser.StringVar(&args.ErrorsFormat, "errors-format", textErrorsFormat,
+		fmt.Sprintf("Also write a machine-readable report of the scanning errors, if there are any. One of: %s, %s, %s", textErrorsFormat, jsonErrorsFormat, sarifErrorsFormat))
+	argparser.StringVar(&args.ErrorsOutput, "errors-output", "", "File to write the --errors-format report to, instead of stdout")
+	argparser.StringVar(&args.ProprietarySoftware, "proprietary-software", "", "Yaml file containing proprietary packages")
+	argparser.StringVar(&args.Profile, "profile", detectlicense.DefaultProfile,
+		fmt.Sprintf("The organization that the license policy is of: one of %s, or a yaml file", strings.Join(detectlicense.ShippedProfiles(), ", ")))
+	argparser.StringArrayVar(&args.LicenseDatabases, "license-database", nil,
+		"Yaml or json license database to layer on top of the built-in one; may be given more than once")
 
 	if err := argparser.Parse(os.Args[1:]); err != nil {
 		return nil, err
@@ -83,14 +147,58 @@ func parseArgs() (*CLIArgs, error) {
 		return nil, fmt.Errorf("--application-type must be one of '%s', '%s'", internalApplication, externalApplication)
 	}
 
+	if args.SourceArchive != "" && args.SourceArchiveName == "" {
+		return nil, fmt.Errorf("--source-archive-name is required for --source-archive")
+	}
+	if args.SourceArchive == "" && args.SourceArchiveName != "" {
+		return nil, fmt.Errorf("--source-archive-name is only valid for --source-archive")
+	}
+
+	switch args.ErrorsFormat {
+	case textErrorsFormat, jsonErrorsFormat, sarifErrorsFormat:
+	default:
+		return nil, fmt.Errorf("--errors-format must be one of '%s', '%s', '%s'", textErrorsFormat, jsonErrorsFormat, sarifErrorsFormat)
+	}
+	if args.ErrorsOutput != "" && args.ErrorsFormat == textErrorsFormat {
+		return nil, fmt.Errorf("--errors-output is only valid for --errors-format=%s or %s", jsonErrorsFormat, sarifErrorsFormat)
+	}
+
 	return args, nil
 }
 
+// writeSourceArchive writes the complete source of the weak-copyleft
+// packages in the license-checker output to the --source-archive file.
+func writeSourceArchive(args *CLIArgs, input []byte) error {
+	files, manifest, err := dependency.GetCompleteSource(bytes.NewReader(input))
+	if err != nil {
+		return err
+	}
+	files[archive.ManifestFilename], err = manifest.Marshal()
+	if err != nil {
+		return err
+	}
+	mtime, err := archive.SourceDateEpoch()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(args.SourceArchive)
+	if err != nil {
+		return err
+	}
+	if err := archive.WriteTar(file, args.SourceArchiveName, files, mtime, true); err != nil {
+		_ = file.Close()
+		_ = os.Remove(args.SourceArchive)
+		return err
+	}
+	return file.Close()
+}
+
 func getLicenseRestriction(applicationType string) detectlicense.LicenseRestriction {
 	var LicenseRestriction detectlicense.LicenseRestriction
 	switch applicationType {
 	case internalApplication:
-		LicenseRestriction = detectlicense.AmbassadorServers
+		LicenseRestriction = detectlicense.InternalUseOnly
 	default:
 		LicenseRestriction = detectlicense.Unrestricted
 	}
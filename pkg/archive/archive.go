@@ -0,0 +1,152 @@
+// Package archive writes the tarballs and zip files of license notices
+// and source code that go-mkopensource and js-mkopensource produce.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The archives that we write are meant to be bit-for-bit reproducible:
+// the same dependencies always produce the same bytes, no matter who
+// runs go-mkopensource, where, or when.  So every piece of metadata in
+// them is fixed, rather than taken from the environment.
+
+const (
+	fileMode = 0644
+	dirMode  = 0755
+	owner    = "root"
+
+	// gzipOSUnknown is the "unknown" operating system in a gzip
+	// header (RFC 1952).
+	gzipOSUnknown = 255
+)
+
+// zipEpoch is the earliest time that the MS-DOS date of a zip entry can
+// hold; anything before it wraps around to a date far in the future.
+//
+//nolint:gochecknoglobals // Would be 'const'.
+var zipEpoch = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// SourceDateEpoch returns the timestamp to give to everything in an
+// archive: the $SOURCE_DATE_EPOCH environment variable (see
+// https://reproducible-builds.org/specs/source-date-epoch/) if it is
+// set, and the Unix epoch otherwise.
+func SourceDateEpoch() (time.Time, error) {
+	str, ok := os.LookupEnv("SOURCE_DATE_EPOCH")
+	if !ok || str == "" {
+		return time.Unix(0, 0).UTC(), nil
+	}
+	secs, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || secs < 0 {
+		return time.Time{}, fmt.Errorf("invalid $SOURCE_DATE_EPOCH %q: must be a non-negative number of seconds", str)
+	}
+	return time.Unix(secs, 0).UTC(), nil
+}
+
+// entries returns the names of the entries of an archive of
+// files inside of the root directory, sorted so that each directory
+// comes before its contents.  Directories have a trailing "/".
+func entries(root string, files map[string][]byte) []string {
+	set := make(map[string]struct{}, len(files))
+	for filename := range files {
+		name := root + "/" + filename
+		set[name] = struct{}{}
+		for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			set[dir+"/"] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteTar writes files to w as a PAX tarball, optionally
+// gzip-compressed, with all of them inside of the root directory.
+func WriteTar(w io.Writer, root string, files map[string][]byte, mtime time.Time, compress bool) error {
+	if compress {
+		gzipWriter := gzip.NewWriter(w)
+		gzipWriter.ModTime = mtime
+		gzipWriter.OS = gzipOSUnknown
+		if err := WriteTar(gzipWriter, root, files, mtime, false); err != nil {
+			return err
+		}
+		return gzipWriter.Close()
+	}
+
+	tarWriter := tar.NewWriter(w)
+	for _, name := range entries(root, files) {
+		header := &tar.Header{
+			Name:    name,
+			ModTime: mtime,
+			Uid:     0,
+			Gid:     0,
+			Uname:   owner,
+			Gname:   owner,
+			Format:  tar.FormatPAX,
+		}
+		if strings.HasSuffix(name, "/") {
+			header.Typeflag = tar.TypeDir
+			header.Mode = dirMode
+		} else {
+			header.Typeflag = tar.TypeReg
+			header.Mode = fileMode
+			header.Size = int64(len(files[strings.TrimPrefix(name, root+"/")]))
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tarWriter.Write(files[strings.TrimPrefix(name, root+"/")]); err != nil {
+				return err
+			}
+		}
+	}
+	return tarWriter.Close()
+}
+
+// WriteZip writes files to w as a zip file, with all of them inside of
+// the root directory.  An mtime before 1980 (such as the default
+// SourceDateEpoch) is 1980-01-01, which is as early as a zip file can
+// say.
+func WriteZip(w io.Writer, root string, files map[string][]byte, mtime time.Time) error {
+	if mtime.Before(zipEpoch) {
+		mtime = zipEpoch
+	}
+	zipWriter := zip.NewWriter(w)
+	for _, name := range entries(root, files) {
+		header := &zip.FileHeader{
+			Name:     name,
+			Modified: mtime,
+		}
+		if strings.HasSuffix(name, "/") {
+			header.SetMode(os.ModeDir | dirMode)
+		} else {
+			header.Method = zip.Deflate
+			header.SetMode(fileMode)
+		}
+		entry, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(name, "/") {
+			if _, err := entry.Write(files[strings.TrimPrefix(name, root+"/")]); err != nil {
+				return err
+			}
+		}
+	}
+	return zipWriter.Close()
+}
@@ -0,0 +1,34 @@
+package archive_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/go-mkopensource/pkg/archive"
+)
+
+func TestWriteZip_defaultEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	mtime, err := archive.SourceDateEpoch()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, archive.WriteZip(&buf, "root", map[string][]byte{"LICENSE": []byte("text\n")}, mtime))
+	zipFile, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	// The Unix epoch can't be stored as an MS-DOS date, which would
+	// wrap around to 2098.
+	require.Len(t, zipFile.File, 2)
+	zipEpoch := time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for _, file := range zipFile.File {
+		//nolint:staticcheck // ModTime is the MS-DOS date that we are checking.
+		assert.True(t, file.ModTime().Equal(zipEpoch), "%s: wrong MS-DOS mtime %v", file.Name, file.ModTime())
+		assert.True(t, file.Modified.Equal(zipEpoch), "%s: wrong mtime %v", file.Name, file.Modified)
+	}
+}
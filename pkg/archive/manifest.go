@@ -0,0 +1,90 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+)
+
+// ManifestFilename is the name of the manifest inside of an archive.
+const ManifestFilename = "SOURCES.json"
+
+// Manifest describes the complete corresponding source code of the
+// packages in an archive, so that anybody can check that what they
+// were given is exactly what was built.
+type Manifest struct {
+	Packages []PackageSource `json:"packages"`
+}
+
+// PackageSource is the complete source code of a single package (a Go
+// module, or an npm package).
+type PackageSource struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Sum is the checksum of the package as a whole, as recorded by
+	// its package manager: the "h1:" hash from go.sum, or the
+	// "integrity" of an npm package.
+	Sum    string `json:"sum,omitempty"`
+	Origin Origin `json:"origin"`
+	// Files are the files of the package, with the same names as in
+	// the archive.
+	Files []FileHash `json:"files"`
+}
+
+// Origin is where the source code of a package came from.
+type Origin struct {
+	// Source is how we got the source code: "module-cache" (a module
+	// zip from the Go module cache), "directory" (a directory on
+	// disk that a Go module was replaced with), or "node_modules".
+	Source string `json:"source"`
+	// Location identifies what was read: "path@version" for a module
+	// zip, the replacement path for a directory, or, for an npm
+	// package, the tarball URL that npm installed it from (or its
+	// path in node_modules if that isn't known).
+	Location string `json:"location,omitempty"`
+	// VCS, URL, Ref and Hash identify the upstream repository and
+	// commit, if known.
+	VCS  string `json:"vcs,omitempty"`
+	URL  string `json:"url,omitempty"`
+	Ref  string `json:"ref,omitempty"`
+	Hash string `json:"hash,omitempty"`
+}
+
+// FileHash is the SHA-256 of a file.
+type FileHash struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+// HashFiles returns the hash of each of the files, sorted by name.
+func HashFiles(files map[string][]byte) []FileHash {
+	hashes := make([]FileHash, 0, len(files))
+	for name, body := range files {
+		sum := sha256.Sum256(body)
+		hashes = append(hashes, FileHash{Name: name, SHA256: hex.EncodeToString(sum[:])})
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return hashes[i].Name < hashes[j].Name
+	})
+	return hashes
+}
+
+// Marshal returns the manifest as indented JSON, with the packages
+// sorted by name and version.
+func (m Manifest) Marshal() ([]byte, error) {
+	if m.Packages == nil {
+		m.Packages = []PackageSource{}
+	}
+	sort.Slice(m.Packages, func(i, j int) bool {
+		if m.Packages[i].Name != m.Packages[j].Name {
+			return m.Packages[i].Name < m.Packages[j].Name
+		}
+		return m.Packages[i].Version < m.Packages[j].Version
+	})
+	body, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(body, '\n'), nil
+}
@@ -0,0 +1,78 @@
+package archive_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/go-mkopensource/pkg/archive"
+)
+
+func TestManifestMarshal(t *testing.T) {
+	manifest := archive.Manifest{
+		Packages: []archive.PackageSource{
+			{
+				Name:    "example.com/b",
+				Version: "v1.0.0",
+				Origin:  archive.Origin{Source: "directory", Location: "./b"},
+				Files:   archive.HashFiles(map[string][]byte{"example.com/b/b.go": []byte("package b\n")}),
+			},
+			{
+				Name:    "example.com/a",
+				Version: "v1.0.0",
+				Sum:     "h1:abc=",
+				Origin:  archive.Origin{Source: "module-cache", Location: "example.com/a@v1.0.0"},
+				Files: archive.HashFiles(map[string][]byte{
+					"example.com/a/z.go":    []byte("package a\n"),
+					"example.com/a/LICENSE": []byte(""),
+				}),
+			},
+		},
+	}
+
+	body, err := manifest.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "packages": [
+    {
+      "name": "example.com/a",
+      "version": "v1.0.0",
+      "sum": "h1:abc=",
+      "origin": {
+        "source": "module-cache",
+        "location": "example.com/a@v1.0.0"
+      },
+      "files": [
+        {
+          "name": "example.com/a/LICENSE",
+          "sha256": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+        },
+        {
+          "name": "example.com/a/z.go",
+          "sha256": "7b39baa38a2ec2b8d111bbbd8e448e80226477ab40105d9d2123d4dc18067438"
+        }
+      ]
+    },
+    {
+      "name": "example.com/b",
+      "version": "v1.0.0",
+      "origin": {
+        "source": "directory",
+        "location": "./b"
+      },
+      "files": [
+        {
+          "name": "example.com/b/b.go",
+          "sha256": "983aab874348ab0e62d9fa51e0719b12f570234284c1f21c740bb6d3ca7cf11d"
+        }
+      ]
+    }
+  ]
+}
+`, string(body))
+
+	empty, err := archive.Manifest{}.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"packages\": []\n}\n", string(empty))
+}
@@ -4,50 +4,69 @@ import (
 	"encoding/json"
 	"fmt"
 	. "github.com/datawire/go-mkopensource/pkg/detectlicense"
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
 )
 
 //nolint:gochecknoglobals // Can't be a constant
 var licensesByName = map[string]License{
-	AmbassadorProprietary.Name: AmbassadorProprietary,
-	ZeroBSD.Name:               ZeroBSD,
-	Apache2.Name:               Apache2,
-	AFL21.Name:                 AFL21,
-	AGPL1Only.Name:             AGPL1Only,
-	AGPL1OrLater.Name:          AGPL1OrLater,
-	AGPL3Only.Name:             AGPL3Only,
-	AGPL3OrLater.Name:          AGPL3OrLater,
-	BSD1.Name:                  BSD1,
-	BSD2.Name:                  BSD2,
-	BSD3.Name:                  BSD3,
-	Cc010.Name:                 Cc010,
-	CcBy30.Name:                CcBy30,
-	CcBy40.Name:                CcBy40,
-	CcBySa40.Name:              CcBySa40,
-	EPL10.Name:                 EPL10,
-	GPL1Only.Name:              GPL1Only,
-	GPL1OrLater.Name:           GPL1OrLater,
-	GPL2Only.Name:              GPL2Only,
-	GPL2OrLater.Name:           GPL2OrLater,
-	GPL3Only.Name:              GPL3Only,
-	GPL3OrLater.Name:           GPL3OrLater,
-	ISC.Name:                   ISC,
-	LGPL2Only.Name:             LGPL2Only,
-	LGPL2OrLater.Name:          LGPL2OrLater,
-	LGPL21Only.Name:            LGPL21Only,
-	LGPL21OrLater.Name:         LGPL21OrLater,
-	LGPL3Only.Name:             LGPL3Only,
-	LGPL3OrLater.Name:          LGPL3OrLater,
-	MIT.Name:                   MIT,
-	MPL11.Name:                 MPL11,
-	MPL2.Name:                  MPL2,
-	ODCBy10.Name:               ODCBy10,
-	OFL11.Name:                 OFL11,
-	PSF.Name:                   PSF,
-	Python20.Name:              Python20,
-	PublicDomain.Name:          PublicDomain,
-	Unicode2015.Name:           Unicode2015,
-	Unlicense.Name:             Unlicense,
-	WTFPL.Name:                 WTFPL,
+	FirstPartyProprietary.Name:    FirstPartyProprietary,
+	AmbassadorProprietary.Name:    AmbassadorProprietary,
+	ZeroBSD.Name:                  ZeroBSD,
+	Apache2.Name:                  Apache2,
+	Apache11.Name:                 Apache11,
+	AFL21.Name:                    AFL21,
+	AGPL1Only.Name:                AGPL1Only,
+	AGPL1OrLater.Name:             AGPL1OrLater,
+	AGPL3Only.Name:                AGPL3Only,
+	AGPL3OrLater.Name:             AGPL3OrLater,
+	Artistic20.Name:               Artistic20,
+	BSD1.Name:                     BSD1,
+	BSD2.Name:                     BSD2,
+	BSD3.Name:                     BSD3,
+	BSD4.Name:                     BSD4,
+	BSL10.Name:                    BSL10,
+	BUSL11.Name:                   BUSL11,
+	Cc010.Name:                    Cc010,
+	CcBy30.Name:                   CcBy30,
+	CcBy40.Name:                   CcBy40,
+	CcBySa40.Name:                 CcBySa40,
+	CommonsClause.Name:            CommonsClause,
+	CDDL10.Name:                   CDDL10,
+	CDDL11.Name:                   CDDL11,
+	EPL10.Name:                    EPL10,
+	EPL20.Name:                    EPL20,
+	Elastic20.Name:                Elastic20,
+	GPL1Only.Name:                 GPL1Only,
+	GPL1OrLater.Name:              GPL1OrLater,
+	GPL2Only.Name:                 GPL2Only,
+	GPL2OrLater.Name:              GPL2OrLater,
+	GPL3Only.Name:                 GPL3Only,
+	GPL3OrLater.Name:              GPL3OrLater,
+	ISC.Name:                      ISC,
+	LGPL2Only.Name:                LGPL2Only,
+	LGPL2OrLater.Name:             LGPL2OrLater,
+	LGPL21Only.Name:               LGPL21Only,
+	LGPL21OrLater.Name:            LGPL21OrLater,
+	LGPL3Only.Name:                LGPL3Only,
+	LGPL3OrLater.Name:             LGPL3OrLater,
+	MIT.Name:                      MIT,
+	MIT0.Name:                     MIT0,
+	MPL11.Name:                    MPL11,
+	MPL2.Name:                     MPL2,
+	ODCBy10.Name:                  ODCBy10,
+	OFL11.Name:                    OFL11,
+	PolyFormNoncommercial100.Name: PolyFormNoncommercial100,
+	PolyFormSmallBusiness100.Name: PolyFormSmallBusiness100,
+	PostgreSQL.Name:               PostgreSQL,
+	PSF.Name:                      PSF,
+	Python20.Name:                 Python20,
+	PublicDomain.Name:             PublicDomain,
+	SSPL10.Name:                   SSPL10,
+	Unicode2015.Name:              Unicode2015,
+	Unlicense.Name:                Unlicense,
+	WTFPL.Name:                    WTFPL,
+	X11.Name:                      X11,
+	Zlib.Name:                     Zlib,
 }
 
 type DependencyInfo struct {
@@ -59,6 +78,16 @@ type Dependency struct {
 	Name     string   `json:"name"`
 	Version  string   `json:"version"`
 	Licenses []string `json:"licenses"`
+	// Copyrights are the copyright notices in the dependency's
+	// license files, which most permissive licenses require to be
+	// reproduced.
+	Copyrights []string `json:"copyrights,omitempty"`
+	// Sum is the go.sum hash ("h1:...") that the dependency's source
+	// code was verified against before it was scanned.
+	Sum string `json:"sum,omitempty"`
+	// Evidence maps each of the Licenses to where it was detected.  It
+	// is only populated in verbose mode.
+	Evidence map[string][]Evidence `json:"evidence,omitempty"`
 }
 
 func NewDependencyInfo() DependencyInfo {
@@ -81,7 +110,7 @@ func (d *DependencyInfo) UpdateLicenseList() error {
 
 	for _, dependency := range d.Dependencies {
 		for _, licenseName := range dependency.Licenses {
-			license, err := getLicenseFromName(licenseName)
+			license, err := GetLicenseFromName(licenseName)
 			if err != nil {
 				return err
 			}
@@ -96,7 +125,9 @@ func (d *DependencyInfo) UpdateLicenseList() error {
 	return nil
 }
 
-func getLicenseFromName(licenseName string) (License, error) {
+// GetLicenseFromName returns the License with the given name, as used
+// in Dependency.Licenses.
+func GetLicenseFromName(licenseName string) (License, error) {
 	license, ok := licensesByName[licenseName]
 	if !ok {
 		return License{}, fmt.Errorf("license details for '%s' are not known", licenseName)
@@ -105,19 +136,26 @@ func getLicenseFromName(licenseName string) (License, error) {
 }
 
 func CheckLicenseRestrictions(dependency Dependency, licenseName string, licenseRestriction LicenseRestriction) error {
-	license, err := getLicenseFromName(licenseName)
+	license, err := GetLicenseFromName(licenseName)
 	if err != nil {
 		return err
 	}
 
 	if license.Restriction == Forbidden {
-		return fmt.Errorf("Dependency '%s@%s' uses license '%s' which is forbidden.", dependency.Name,
-			dependency.Version, license.Name)
+		return &scanningerrors.ForbiddenLicenseError{
+			Name:            dependency.Name,
+			Version:         dependency.Version,
+			License:         license.Name,
+			SourceAvailable: license.SourceAvailable,
+		}
 	}
 
 	if license.Restriction < licenseRestriction {
-		return fmt.Errorf("Dependency '%s@%s' uses license '%s' which is not allowed on applications that run on customer machines.",
-			dependency.Name, dependency.Version, license.Name)
+		return &scanningerrors.RestrictedLicenseError{
+			Name:    dependency.Name,
+			Version: dependency.Version,
+			License: license.Name,
+		}
 	}
 	return nil
 }
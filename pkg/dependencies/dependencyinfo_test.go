@@ -1,8 +1,10 @@
 package dependencies_test
 
 import (
+	"errors"
 	"github.com/datawire/go-mkopensource/pkg/dependencies"
 	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -192,6 +194,16 @@ func TestCheckLicensesValidatesForbiddenLicensesCorrectly(t *testing.T) {
 			detectlicense.GPL3Only.Name,
 			detectlicense.Unrestricted,
 		},
+		{
+			"Source-available licenses are not allowed on Ambassador Labs servers",
+			detectlicense.SSPL10.Name,
+			detectlicense.AmbassadorServers,
+		},
+		{
+			"Source-available licenses are not allowed on customer machines",
+			detectlicense.BUSL11.Name,
+			detectlicense.Unrestricted,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -207,3 +219,39 @@ func TestCheckLicensesValidatesForbiddenLicensesCorrectly(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckLicensesReportsSourceAvailableLicenses(t *testing.T) {
+	testDependency := dependencies.Dependency{
+		Name:    "library1",
+		Version: "1.0.2",
+	}
+
+	err := dependencies.CheckLicenseRestrictions(testDependency, detectlicense.Elastic20.Name, detectlicense.AmbassadorServers)
+
+	require.EqualError(t, err, "Dependency 'library1@1.0.2' uses license 'Elastic License 2.0' which is source-available, not open-source, and is forbidden.")
+}
+
+func TestCheckLicensesReturnsTypedErrors(t *testing.T) {
+	testDependency := dependencies.Dependency{
+		Name:    "library1",
+		Version: "1.0.2",
+	}
+
+	err := dependencies.CheckLicenseRestrictions(testDependency, detectlicense.AGPL3Only.Name, detectlicense.Unrestricted)
+	var forbidden *scanningerrors.ForbiddenLicenseError
+	require.True(t, errors.As(err, &forbidden))
+	require.Equal(t, &scanningerrors.ForbiddenLicenseError{
+		Name:    "library1",
+		Version: "1.0.2",
+		License: detectlicense.AGPL3Only.Name,
+	}, forbidden)
+
+	err = dependencies.CheckLicenseRestrictions(testDependency, detectlicense.GPL3Only.Name, detectlicense.Unrestricted)
+	var restricted *scanningerrors.RestrictedLicenseError
+	require.True(t, errors.As(err, &restricted))
+	require.Equal(t, &scanningerrors.RestrictedLicenseError{
+		Name:    "library1",
+		Version: "1.0.2",
+		License: detectlicense.GPL3Only.Name,
+	}, restricted)
+}
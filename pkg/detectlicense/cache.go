@@ -0,0 +1,198 @@
+package detectlicense
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"sync"
+)
+
+// cacheFormat is the version of the format of the cache entries; bump
+// it to invalidate every existing entry.
+const cacheFormat = "3"
+
+// A Cache is an on-disk cache of the results of
+// DetectLicensesWithEvidence, so that a scan only has to run the
+// detector on the packages that changed since the last one.
+//
+// Entries are keyed by the package's name and version, and the
+// contents of the files that were scanned: the go.sum hash of the
+// module (which the scanner has checked the files against), and a
+// digest of the names of the files (which differ between a vendored
+// package and the build-constrained subset that `go list` reports, and
+// between platforms); or, for a package whose module wasn't verified
+// against go.sum, a digest of the names and contents of the files.
+// They are also keyed by a fingerprint of the scanner's own executable
+// and of the license database, so that changing the detector or the
+// curations invalidates them.  Only successful detections are cached.
+type Cache struct {
+	dir     string
+	scanner string
+
+	mu       sync.Mutex
+	dbPrints map[*Database]string
+}
+
+// DefaultCacheDir returns the directory that the cache is in if none
+// is given: go-mkopensource/ in os.UserCacheDir().
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-mkopensource"), nil
+}
+
+// OpenCache returns the cache in dir, creating it if need be.
+func OpenCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	scanner, err := scannerFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir, scanner: scanner}, nil
+}
+
+// scannerFingerprint returns the hash of the running executable; or,
+// failing that, its build info.
+func scannerFingerprint() (string, error) {
+	hash := sha256.New()
+	if exe, err := os.Executable(); err == nil {
+		if f, err := os.Open(exe); err == nil {
+			defer f.Close()
+			if _, err := io.Copy(hash, f); err == nil {
+				return hex.EncodeToString(hash.Sum(nil)), nil
+			}
+			hash.Reset()
+		}
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" || info.Main.Version == "(devel)" {
+		return "", fmt.Errorf("could not identify the version of the scanner")
+	}
+	_, _ = io.WriteString(hash, info.String())
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// databaseFingerprint returns the hash of a license database.
+func (c *Cache) databaseFingerprint(db *Database) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if dbPrint, ok := c.dbPrints[db]; ok {
+		return dbPrint, dbPrint != ""
+	}
+	dbPrint := ""
+	if data, err := json.Marshal(db); err == nil {
+		sum := sha256.Sum256(data)
+		dbPrint = hex.EncodeToString(sum[:])
+	}
+	if c.dbPrints == nil {
+		c.dbPrints = make(map[*Database]string)
+	}
+	c.dbPrints[db] = dbPrint
+	return dbPrint, dbPrint != ""
+}
+
+type cachedLicense struct {
+	License  License    `json:"license"`
+	Evidence []Evidence `json:"evidence"`
+}
+
+// filesDigest returns the hash of the names of files, and, if
+// withContents, of their contents.
+func filesDigest(files map[string][]byte, withContents bool) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	for _, name := range names {
+		if withContents {
+			_, _ = fmt.Fprintf(hash, "%x  %q\n", sha256.Sum256(files[name]), name)
+		} else {
+			_, _ = fmt.Fprintf(hash, "%q\n", name)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// DetectLicensesWithEvidence is like db.DetectLicensesWithEvidence
+// (or, if db is nil, the function of the same name), but looks up the
+// result in the cache first.  sum is the go.sum hash ("h1:...") that
+// the files have been verified against, or "" if they haven't been.
+// If c is nil, the cache isn't used.
+func (c *Cache) DetectLicensesWithEvidence(db *Database, packageName, packageVersion, sum string, files map[string][]byte) (Detection, error) {
+	if db == nil {
+		db = CurrentDatabase()
+	}
+	if c == nil {
+		return db.DetectLicensesWithEvidence(packageName, packageVersion, files)
+	}
+	dbPrint, ok := c.databaseFingerprint(db)
+	if !ok {
+		return db.DetectLicensesWithEvidence(packageName, packageVersion, files)
+	}
+	contents := "files:" + filesDigest(files, true)
+	if sum != "" {
+		contents = "sum:" + sum + "\x00" + filesDigest(files, false)
+	}
+	key := sha256.Sum256([]byte(cacheFormat + "\x00" + c.scanner + "\x00" + dbPrint + "\x00" +
+		packageName + "\x00" + packageVersion + "\x00" + contents))
+	filename := filepath.Join(c.dir, hex.EncodeToString(key[:1]), hex.EncodeToString(key[:])+".json")
+
+	if data, err := os.ReadFile(filename); err == nil {
+		var entry []cachedLicense
+		if err := json.Unmarshal(data, &entry); err == nil && len(entry) > 0 {
+			detection := make(Detection, len(entry))
+			for _, license := range entry {
+				detection[license.License] = license.Evidence
+			}
+			return detection, nil
+		}
+	}
+
+	detection, err := db.DetectLicensesWithEvidence(packageName, packageVersion, files)
+	if err != nil {
+		return nil, err
+	}
+	entry := make([]cachedLicense, 0, len(detection))
+	for license, evidence := range detection {
+		entry = append(entry, cachedLicense{License: license, Evidence: evidence})
+	}
+	if data, err := json.Marshal(entry); err == nil {
+		// The cache is only an optimization; not being able to write
+		// to it isn't an error.
+		_ = writeFileAtomic(filename, data)
+	}
+	return detection, nil
+}
+
+// writeFileAtomic writes a file such that concurrent readers either see
+// all of it or none of it.
+func writeFileAtomic(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,113 @@
+package detectlicense
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	defer SetDatabase(nil)
+
+	dir := t.TempDir()
+	cache, err := OpenCache(dir)
+	require.NoError(t, err)
+
+	mit, ok := CanonicalText(MIT)
+	require.True(t, ok)
+	files := map[string][]byte{
+		"example.com/lib/LICENSE": mit,
+		"example.com/lib/lib.go":  []byte("package lib\n"),
+	}
+	expected := Detection{MIT: {NewEvidence(EvidenceLicenseFile, "example.com/lib/LICENSE", mit)}}
+	entries := func() int {
+		t.Helper()
+		entries, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+		require.NoError(t, err)
+		return len(entries)
+	}
+
+	detection, err := cache.DetectLicensesWithEvidence(nil, "example.com/lib", "v1.0.0", "", files)
+	require.NoError(t, err)
+	require.Equal(t, expected, detection)
+	require.Equal(t, 1, entries())
+
+	// The same files are looked up in the cache, rather than detected
+	// again
+	bsd, ok := CanonicalText(BSD3)
+	require.True(t, ok)
+	entry, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	require.NoError(t, err)
+	tampered, err := json.Marshal([]cachedLicense{{License: BSD3}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(entry[0], tampered, 0o644))
+	detection, err = cache.DetectLicensesWithEvidence(nil, "example.com/lib", "v1.0.0", "", files)
+	require.NoError(t, err)
+	require.Equal(t, Detection{BSD3: nil}, detection)
+
+	// Different files, such as those of another platform or of the
+	// vendored package, aren't
+	otherFiles := map[string][]byte{
+		"example.com/lib/LICENSE":    mit,
+		"example.com/lib/lib.go":     []byte("package lib\n"),
+		"example.com/lib/lib_bsd.go": append([]byte("//go:build freebsd\n\n/*\n"), append(bsd, []byte("*/\n\npackage lib\n")...)...),
+	}
+	_, err = cache.DetectLicensesWithEvidence(nil, "example.com/lib", "v1.0.0", "", otherFiles)
+	require.NoError(t, err)
+	require.Equal(t, 2, entries())
+
+	// Neither is a different version
+	_, err = cache.DetectLicensesWithEvidence(nil, "example.com/lib", "v1.0.1", "", files)
+	require.NoError(t, err)
+	require.Equal(t, 3, entries())
+
+	// Nor a different license database
+	db := DefaultDatabase()
+	db.Merge(&Database{IgnoredFiles: map[string]string{"example.com/other/LICENSE": "Not a license"}})
+	detection, err = cache.DetectLicensesWithEvidence(db, "example.com/lib", "v1.0.0", "", files)
+	require.NoError(t, err)
+	require.Equal(t, expected, detection)
+	require.Equal(t, 4, entries())
+	SetDatabase(db)
+	_, err = cache.DetectLicensesWithEvidence(nil, "example.com/lib", "v1.0.0", "", files)
+	require.NoError(t, err)
+	require.Equal(t, 4, entries())
+
+	// Files that have been verified against go.sum are cached by the
+	// go.sum hash and the names of the files, so other contents with
+	// the same hash (which verification would have refused) hit the
+	// same entry; but a different hash doesn't
+	const sum = "h1:0123456789abcdefghijklmnopqrstuvwxyzABCDEFG="
+	_, err = cache.DetectLicensesWithEvidence(nil, "example.com/lib", "v1.0.0", sum, files)
+	require.NoError(t, err)
+	require.Equal(t, 5, entries())
+	sameNames := map[string][]byte{
+		"example.com/lib/LICENSE": bsd,
+		"example.com/lib/lib.go":  []byte("package lib\n"),
+	}
+	detection, err = cache.DetectLicensesWithEvidence(nil, "example.com/lib", "v1.0.0", sum, sameNames)
+	require.NoError(t, err)
+	require.Equal(t, expected, detection)
+	require.Equal(t, 5, entries())
+	_, err = cache.DetectLicensesWithEvidence(nil, "example.com/lib", "v1.0.0", "h1:other=", files)
+	require.NoError(t, err)
+	require.Equal(t, 6, entries())
+	// and different names, with the same hash, don't either
+	_, err = cache.DetectLicensesWithEvidence(nil, "example.com/lib", "v1.0.0", sum, otherFiles)
+	require.NoError(t, err)
+	require.Equal(t, 7, entries())
+
+	// Errors aren't cached
+	noLicense := map[string][]byte{"example.com/lib/lib.go": []byte("package lib\n")}
+	_, err = cache.DetectLicensesWithEvidence(nil, "example.com/lib", "v1.0.0", "", noLicense)
+	require.Error(t, err)
+	require.Equal(t, 7, entries())
+
+	// A nil cache doesn't cache
+	detection, err = (*Cache)(nil).DetectLicensesWithEvidence(nil, "example.com/lib", "v1.0.0", "", files)
+	require.NoError(t, err)
+	require.Equal(t, expected, detection)
+}
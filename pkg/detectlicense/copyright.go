@@ -0,0 +1,40 @@
+package detectlicense
+
+import (
+	"regexp"
+	"strings"
+)
+
+//nolint:gochecknoglobals // Would be 'const'.
+var (
+	// reCopyrightLine matches a line that starts with a copyright
+	// notice, ignoring any comment or indentation characters before it.
+	reCopyrightLine = regexp.MustCompile(`(?m)^[\s*/#;>-]*((?:Portions )?(?:Copyright|COPYRIGHT)\s*(?:\([cC]\)|©)?[^\n]*)$`)
+
+	// reCopyrightBoilerplate matches lines that start with "Copyright"
+	// but are part of the text of a license rather than a notice
+	// ("Copyright License", "copyright holders and contributors", a
+	// "Copyright [yyyy] [name of copyright owner]" template, ...).
+	reCopyrightBoilerplate = regexp.MustCompile(`(?i)^(?:Portions )?Copyright\s*(?:\(c\)|©)?\s*(?:$|(?:holders?|notices?|license|owners?|and|law|act|statement|protection|infringement)\b|[\[<{])|[\[<{](?:yyyy|year|name of copyright owner|copyright holders?|owner)[\]>}]`)
+)
+
+// ExtractCopyrights takes the contents of a license-file and returns the
+// copyright notices in it (for example "Copyright (c) 2015 Jane Doe"),
+// in the order that they appear.  Permissive licenses like MIT and BSD
+// require that these notices be reproduced along with the license.
+func ExtractCopyrights(body []byte) []string {
+	var copyrights []string
+	seen := make(map[string]struct{})
+	for _, match := range reCopyrightLine.FindAllSubmatch(body, -1) {
+		copyright := strings.Join(strings.Fields(string(match[1])), " ")
+		if reCopyrightBoilerplate.MatchString(copyright) {
+			continue
+		}
+		if _, dup := seen[copyright]; dup {
+			continue
+		}
+		seen[copyright] = struct{}{}
+		copyrights = append(copyrights, copyright)
+	}
+	return copyrights
+}
@@ -0,0 +1,73 @@
+package detectlicense_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+)
+
+func TestExtractCopyrights(t *testing.T) {
+	testcases := map[string]struct {
+		body     string
+		expected []string
+	}{
+		"mit": {
+			body: `The MIT License (MIT)
+
+Copyright (c) 2014 Simon Eskildsen
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+`,
+			expected: []string{"Copyright (c) 2014 Simon Eskildsen"},
+		},
+		"several-holders": {
+			body: `Copyright (c) 2011-2013, 'pq' Contributors
+Portions Copyright (C) 2011 Blake Mizerany
+  Copyright ©  2015   Jane Doe
+Copyright (c) 2011-2013, 'pq' Contributors
+`,
+			expected: []string{
+				"Copyright (c) 2011-2013, 'pq' Contributors",
+				"Portions Copyright (C) 2011 Blake Mizerany",
+				"Copyright © 2015 Jane Doe",
+			},
+		},
+		"comment-block": {
+			body: `/*
+ * Copyright The containerd Authors.
+ */
+`,
+			expected: []string{"Copyright The containerd Authors."},
+		},
+		"boilerplate": {
+			body: `   Copyright [yyyy] [name of copyright owner]
+Copyright (c) <year> <copyright holders>
+COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED
+Copyright License. Subject to the terms and conditions of this License,
+`,
+			expected: nil,
+		},
+	}
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			copyrights := detectlicense.ExtractCopyrights([]byte(tc.body))
+			if !reflect.DeepEqual(copyrights, tc.expected) {
+				t.Errorf("wrong result:\n"+
+					"expected: %q\n"+
+					"received: %q\n",
+					tc.expected,
+					copyrights)
+			}
+		})
+	}
+}
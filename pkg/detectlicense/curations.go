@@ -0,0 +1,96 @@
+package detectlicense
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A Curation is the licenses that a human has reviewed a license file
+// to have, when IdentifyLicenses can't identify them.  Curations are
+// keyed by the CurationKey of the file, so that a single review covers
+// every module and version that ships the same text.
+type Curation struct {
+	Licenses []License
+	// Reason is why the file has those licenses; for instance, how it
+	// differs from the canonical text.
+	Reason string
+}
+
+// UnmarshalYAML reads a Curation with the SPDX identifiers of its
+// licenses.
+func (c *Curation) UnmarshalYAML(node *yaml.Node) error {
+	var raw struct {
+		Licenses []string `yaml:"licenses"`
+		Reason   string   `yaml:"reason"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	if err := checkFields(node, "detectlicense.Curation", "licenses", "reason"); err != nil {
+		return err
+	}
+	licenses, err := parseSPDXIDs(node, raw.Licenses)
+	if err != nil {
+		return err
+	}
+	*c = Curation{Licenses: licenses, Reason: raw.Reason}
+	return nil
+}
+
+// CurationKey returns the key of a license file in Curations: the hex
+// SHA-256 of the file, normalized so that line endings and trailing
+// whitespace don't matter.
+func CurationKey(body []byte) string {
+	sum := sha256.Sum256(normalizeLicenseFile(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeLicenseFile converts the line endings to "\n", removes the
+// whitespace at the end of each line and the blank lines at the start
+// and end of the file, and ends the file with a single "\n".
+func normalizeLicenseFile(body []byte) []byte {
+	body = bytes.ReplaceAll(body, []byte("\r\n"), []byte("\n"))
+	body = bytes.ReplaceAll(body, []byte("\r"), []byte("\n"))
+	lines := bytes.Split(body, []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimRight(line, " \t\f\v")
+	}
+	return append(bytes.Trim(bytes.Join(lines, []byte("\n")), "\n"), '\n')
+}
+
+// validateCurationKeys returns an error if any of the keys of curations
+// isn't a lowercase hex SHA-256.
+func validateCurationKeys(curations map[string]Curation) error {
+	for key := range curations {
+		if _, err := hex.DecodeString(key); err != nil || len(key) != 2*sha256.Size || key != strings.ToLower(key) {
+			return fmt.Errorf("curation %q: must be the lowercase hex SHA-256 of a license file", key)
+		}
+	}
+	return nil
+}
+
+// IdentifyCuratedLicenses returns the licenses of a license file that
+// IdentifyLicenses can't identify, if it has been curated in
+// CurrentDatabase.
+func IdentifyCuratedLicenses(body []byte) (map[License]struct{}, bool) {
+	return CurrentDatabase().IdentifyCuratedLicenses(body)
+}
+
+// IdentifyCuratedLicenses returns the licenses of a license file that
+// IdentifyLicenses can't identify, if it has been curated in d.
+func (d *Database) IdentifyCuratedLicenses(body []byte) (map[License]struct{}, bool) {
+	curation, ok := d.Curations[CurationKey(body)]
+	if !ok {
+		return nil, false
+	}
+	licenses := make(map[License]struct{}, len(curation.Licenses))
+	for _, license := range curation.Licenses {
+		licenses[license] = struct{}{}
+	}
+	return licenses, true
+}
@@ -0,0 +1,78 @@
+package detectlicense
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const curatedLicense = "Copyright (c) 2020 Example Corp\n" +
+	"\n" +
+	"Permission is granted to use, copy, modify and distribute this\n" +
+	"software for any purpose, provided that this notice is kept.\n"
+
+func TestCurationKey(t *testing.T) {
+	key := CurationKey([]byte(curatedLicense))
+	require.Len(t, key, 64)
+
+	// Line endings and trailing whitespace don't matter
+	require.Equal(t, key, CurationKey([]byte("\r\nCopyright (c) 2020 Example Corp  \r\n"+
+		"\r\n"+
+		"Permission is granted to use, copy, modify and distribute this\t\r\n"+
+		"software for any purpose, provided that this notice is kept.")))
+	// but the words do
+	require.NotEqual(t, key, CurationKey([]byte("Copyright (c) 2021 Example Corp\n"+
+		"\n"+
+		"Permission is granted to use, copy, modify and distribute this\n"+
+		"software for any purpose, provided that this notice is kept.\n")))
+	// and so do line breaks
+	require.NotEqual(t, key, CurationKey([]byte("Copyright (c) 2020 Example Corp\n"+
+		"\n"+
+		"Permission is granted to use, copy, modify and distribute this software\n"+
+		"for any purpose, provided that this notice is kept.\n")))
+}
+
+func TestCurations(t *testing.T) {
+	defer SetDatabase(nil)
+
+	files := map[string][]byte{
+		"example.com/lib/LICENSE": []byte(curatedLicense),
+		"example.com/lib/lib.go":  []byte("package lib\n"),
+	}
+	_, err := DetectLicenses("example.com/lib", "v1.0.0", files)
+	require.Error(t, err)
+
+	// A store of curations, with a file per curation
+	store := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(store, "example-corp.yaml"), []byte(""+
+		"version: 1\n"+
+		"curations:\n"+
+		"  "+CurationKey([]byte(curatedLicense))+":\n"+
+		"    licenses: [ISC]\n"+
+		"    reason: The ISC license, reworded\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(store, "README.md"), []byte("Not a database\n"), 0o644))
+	db, err := LoadDatabase(store)
+	require.NoError(t, err)
+	SetDatabase(db)
+
+	// covers every version of every package with the same text
+	for _, pkg := range []string{"example.com/lib", "example.com/fork"} {
+		detection, err := DetectLicensesWithEvidence(pkg, "v2.0.0", map[string][]byte{
+			pkg + "/LICENSE.txt": []byte(curatedLicense),
+			pkg + "/lib.go":      []byte("package lib\n"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, Detection{ISC: {NewEvidence(EvidenceCuration, pkg+"/LICENSE.txt", []byte(curatedLicense))}}, detection)
+	}
+}
+
+func TestLoadDatabase_invalidCuration(t *testing.T) {
+	for _, key := range []string{"0123abcd", CurationKey(nil)[:63] + "X", "A" + CurationKey(nil)[1:]} {
+		filename := filepath.Join(t.TempDir(), "database.yaml")
+		require.NoError(t, os.WriteFile(filename, []byte("version: 1\ncurations:\n  "+key+":\n    licenses: [MIT]\n"), 0o644))
+		_, err := LoadDatabase(filename)
+		require.Error(t, err, key)
+	}
+}
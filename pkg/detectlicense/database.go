@@ -0,0 +1,218 @@
+package detectlicense
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DatabaseVersion is the version of the license database file format
+// that this version of go-mkopensource reads.
+const DatabaseVersion = 1
+
+// database.yaml is the built-in license database.
+//
+//go:embed database.yaml
+var builtinDatabase []byte
+
+// A Database is what license detection knows about dependencies beyond
+// the license texts: the licenses of dependencies that can't be
+// detected, and the files that look like license files but aren't.
+type Database struct {
+	Version int `yaml:"version"`
+	// IgnoredFiles maps the files that aren't license files, despite
+	// their names, to why they aren't.
+	IgnoredFiles map[string]string `yaml:"ignoredFiles"`
+	// Go and Npm are the overrides for Go packages and npm packages.
+	Go  Overrides `yaml:"go"`
+	Npm Overrides `yaml:"npm"`
+	// Curations are the licenses of license files that have been
+	// reviewed by a human, keyed by their CurationKey.
+	Curations map[string]Curation `yaml:"curations"`
+}
+
+//nolint:gochecknoglobals // Set once, by SetDatabase, before detection.
+var (
+	databaseMu sync.RWMutex
+	database   *Database
+)
+
+// DefaultDatabase returns the built-in license database.
+func DefaultDatabase() *Database {
+	db, err := parseDatabase(builtinDatabase)
+	if err != nil {
+		panic(fmt.Errorf("built-in license database: %w", err))
+	}
+	return db
+}
+
+// LoadDatabase returns the built-in license database, with the
+// databases in the YAML or JSON files called filenames layered on top
+// of it, in order.  A directory is the same as its .yaml, .yml and
+// .json files, in lexical order; so that a shared store of curations
+// can be a git repository, with a file per curation.
+func LoadDatabase(filenames ...string) (*Database, error) {
+	var files []string
+	for _, filename := range filenames {
+		entries, err := os.ReadDir(filename)
+		if err != nil {
+			// not a directory
+			files = append(files, filename)
+			continue
+		}
+		for _, entry := range entries {
+			switch filepath.Ext(entry.Name()) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(filename, entry.Name()))
+				}
+			}
+		}
+	}
+
+	db := DefaultDatabase()
+	for _, filename := range files {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		layer, err := parseDatabase(data)
+		if err != nil {
+			return nil, fmt.Errorf("license database %s: %w", filename, err)
+		}
+		db.Merge(layer)
+	}
+	return db, nil
+}
+
+func parseDatabase(data []byte) (*Database, error) {
+	var db Database
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&db); err != nil {
+		return nil, err
+	}
+	if db.Version != DatabaseVersion {
+		return nil, fmt.Errorf("version is %d, but only version %d is supported", db.Version, DatabaseVersion)
+	}
+	if err := db.Go.Validate(); err != nil {
+		return nil, err
+	}
+	if err := db.Npm.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateCurationKeys(db.Curations); err != nil {
+		return nil, err
+	}
+	return &db, nil
+}
+
+// Merge adds the entries of other to d; where both have an entry for
+// the same key, other's wins.
+func (d *Database) Merge(other *Database) {
+	if d.IgnoredFiles == nil {
+		d.IgnoredFiles = make(map[string]string)
+	}
+	for filename, reason := range other.IgnoredFiles {
+		d.IgnoredFiles[filename] = reason
+	}
+	if d.Go == nil {
+		d.Go = make(Overrides)
+	}
+	for key, override := range other.Go {
+		d.Go[key] = override
+	}
+	if d.Npm == nil {
+		d.Npm = make(Overrides)
+	}
+	for key, override := range other.Npm {
+		d.Npm[key] = override
+	}
+	if d.Curations == nil {
+		d.Curations = make(map[string]Curation)
+	}
+	for key, curation := range other.Curations {
+		d.Curations[key] = curation
+	}
+}
+
+// checkFields returns an error if a mapping has fields other than the
+// given ones; node.Decode doesn't check for unknown fields, even if
+// the decoder does.
+func checkFields(node *yaml.Node, typeName string, fields ...string) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i]; !slices.Contains(fields, key.Value) {
+			return fmt.Errorf("line %d: field %s not found in type %s", key.Line, key.Value, typeName)
+		}
+	}
+	return nil
+}
+
+// parseSPDXIDs returns the licenses with the given SPDX identifiers.
+func parseSPDXIDs(node *yaml.Node, ids []string) ([]License, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("line %d: no licenses", node.Line)
+	}
+	licenses := make([]License, 0, len(ids))
+	for _, id := range ids {
+		license, ok := SpdxIdentifiers[id]
+		if !ok {
+			return nil, fmt.Errorf("line %d: %q is not a valid SPDX License identifier. See https://spdx.org/licenses/ for a full list", node.Line, id)
+		}
+		licenses = append(licenses, license)
+	}
+	return licenses, nil
+}
+
+// SetDatabase sets the license database that the package-level
+// detection functions use; until it is called, that is the built-in
+// one.  A program that uses more than one database uses the Database's
+// own methods instead.
+func SetDatabase(db *Database) {
+	databaseMu.Lock()
+	defer databaseMu.Unlock()
+	database = db
+}
+
+// CurrentDatabase returns the license database that the package-level
+// detection functions use.
+func CurrentDatabase() *Database {
+	databaseMu.RLock()
+	db := database
+	databaseMu.RUnlock()
+	if db != nil {
+		return db
+	}
+
+	databaseMu.Lock()
+	defer databaseMu.Unlock()
+	if database == nil {
+		database = DefaultDatabase()
+	}
+	return database
+}
+
+// knownDependencies will return a list of licenses for any dependency that has been
+// hardcoded due to the difficulty to parse the license file(s).
+func (d *Database) knownDependencies(dependencyName string, dependencyVersion string, files map[string][]byte) (licenses []License, ok bool) {
+	override, ok := d.Go.Lookup(dependencyName, dependencyVersion)
+	if !ok {
+		return nil, false
+	}
+	var licenseFiles [][]byte
+	for filename, body := range files {
+		if IsLicenseFile(filename) {
+			licenseFiles = append(licenseFiles, body)
+		}
+	}
+	if !override.Covers(licenseFiles...) {
+		return nil, false
+	}
+	return override.Licenses, true
+}
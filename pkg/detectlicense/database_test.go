@@ -0,0 +1,101 @@
+package detectlicense
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDatabase(t *testing.T) {
+	dir := t.TempDir()
+	yamlLayer := filepath.Join(dir, "team.yaml")
+	require.NoError(t, os.WriteFile(yamlLayer, []byte(""+
+		"version: 1\n"+
+		"ignoredFiles:\n"+
+		"  example.com/lib/LICENSE.tmpl: A template\n"+
+		"npm:\n"+
+		"  cyclist@0.2.2:\n"+
+		"    licenses: [BSD-3-Clause]\n"), 0o644))
+	jsonLayer := filepath.Join(dir, "mine.json")
+	require.NoError(t, os.WriteFile(jsonLayer, []byte(`{
+  "version": 1,
+  "go": {
+    "example.com/lib@>=v1.0.0 <v2.0.0": {"licenses": ["MIT"], "licenseSHA256": "0123abcd"}
+  }
+}`), 0o644))
+
+	db, err := LoadDatabase(yamlLayer, jsonLayer)
+	require.NoError(t, err)
+
+	// The built-in database is still there
+	require.Contains(t, db.IgnoredFiles, "github.com/miekg/dns/COPYRIGHT")
+	_, ok := db.Go.Lookup("github.com/garyburd/redigo/redis", "v0.0.0-20150301180006-535138d7bcd7")
+	require.True(t, ok)
+	// only for the version that was checked.
+	_, ok = db.Go.Lookup("github.com/garyburd/redigo/redis", "v0.0.0-20160101000000-0123456789ab")
+	require.False(t, ok)
+
+	// with the layers on top
+	require.Equal(t, "A template", db.IgnoredFiles["example.com/lib/LICENSE.tmpl"])
+	override, ok := db.Npm.Lookup("cyclist", "0.2.2")
+	require.True(t, ok)
+	require.Equal(t, []License{BSD3}, override.Licenses)
+	override, ok = db.Go.Lookup("example.com/lib", "v1.2.3")
+	require.True(t, ok)
+	require.Equal(t, Override{Licenses: []License{MIT}, LicenseSHA256: "0123abcd"}, override)
+
+	// and the built-in database isn't changed
+	override, ok = DefaultDatabase().Npm.Lookup("cyclist", "0.2.2")
+	require.True(t, ok)
+	require.Equal(t, []License{MIT}, override.Licenses)
+}
+
+func TestLoadDatabase_invalid(t *testing.T) {
+	testcases := map[string]string{
+		"no-version":   "go: {}\n",
+		"new-version":  "version: 2\n",
+		"bad-key":      "version: 1\ngo:\n  example.com/lib:\n    licenses: [MIT]\n",
+		"bad-range":    "version: 1\ngo:\n  example.com/lib@>=v1.0.0 ~v2:\n    licenses: [MIT]\n",
+		"bad-spdx":     "version: 1\ngo:\n  example.com/lib@v1.0.0:\n    licenses: [MIT-ish]\n",
+		"no-licenses":  "version: 1\nnpm:\n  lib@1.0.0:\n    reason: It's fine\n",
+		"unknown-key":  "version: 1\npython: {}\n",
+		"unknown-key2": "version: 1\nnpm:\n  lib@1.0.0:\n    licenses: [MIT]\n    licence: MIT\n",
+	}
+	for name, content := range testcases {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "database.yaml")
+			require.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+			_, err := LoadDatabase(filename)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSetDatabase(t *testing.T) {
+	defer SetDatabase(nil)
+
+	files := map[string][]byte{
+		"example.com/lib/LICENSE":      []byte("Not a license at all, just a template\n"),
+		"example.com/lib/lib.go":       []byte("package lib\n"),
+		"example.com/other/LICENSE.md": []byte("Do what you want.\n"),
+	}
+	_, err := DetectLicenses("example.com/lib", "v1.0.0", files)
+	require.Error(t, err)
+
+	db := DefaultDatabase()
+	db.Merge(&Database{
+		IgnoredFiles: map[string]string{"example.com/lib/LICENSE": "A template"},
+		Go:           Overrides{"example.com/lib@>=v1.0.0": {Licenses: []License{Apache2}}},
+	})
+	SetDatabase(db)
+	licenses, err := DetectLicenses("example.com/lib", "v1.0.0", files)
+	require.NoError(t, err)
+	require.Equal(t, map[License]struct{}{Apache2: {}}, licenses)
+
+	// Only while the license file is unchanged
+	db.Go["example.com/lib@>=v1.0.0"] = Override{Licenses: []License{Apache2}, LicenseSHA256: "0123abcd"}
+	_, err = DetectLicenses("example.com/lib", "v1.0.0", files)
+	require.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package detectlicense_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
+)
+
+func TestDetectLicensesTypedErrors(t *testing.T) {
+	t.Run("unknown SPDX identifier", func(t *testing.T) {
+		files := map[string][]byte{
+			"example.com/lib/lib.go": []byte("// SPDX-License-Identifier: Not-A-License\npackage lib\n"),
+		}
+		_, err := detectlicense.DetectLicenses("example.com/lib", "v1.0.0", files)
+		var spdxErr *scanningerrors.UnknownSPDXError
+		if !errors.As(err, &spdxErr) {
+			t.Fatalf("expected an *UnknownSPDXError, got %#v", err)
+		}
+		expected := &scanningerrors.UnknownSPDXError{
+			Name:    "example.com/lib",
+			Version: "v1.0.0",
+			File:    "example.com/lib/lib.go",
+			ID:      "Not-A-License",
+		}
+		if !reflect.DeepEqual(spdxErr, expected) {
+			t.Errorf("wrong result:\nexpected: %#v\nreceived: %#v\n", expected, spdxErr)
+		}
+	})
+
+	t.Run("no license file", func(t *testing.T) {
+		files := map[string][]byte{
+			"example.com/lib/b.go": []byte("package lib\n"),
+			"example.com/lib/a.go": []byte("package lib\n"),
+		}
+		_, err := detectlicense.DetectLicenses("example.com/lib", "v1.0.0", files)
+		var detectionErr *scanningerrors.DetectionError
+		if !errors.As(err, &detectionErr) {
+			t.Fatalf("expected a *DetectionError, got %#v", err)
+		}
+		expected := []string{"example.com/lib/a.go", "example.com/lib/b.go"}
+		if !reflect.DeepEqual(detectionErr.Files, expected) {
+			t.Errorf("wrong result:\nexpected: %s\nreceived: %s\n", expected, detectionErr.Files)
+		}
+	})
+
+	t.Run("unexpected NOTICE file", func(t *testing.T) {
+		files := map[string][]byte{
+			"example.com/lib/lib.go": []byte("// SPDX-License-Identifier: MIT\npackage lib\n"),
+			"example.com/lib/NOTICE": []byte("Some notice\n"),
+		}
+		_, err := detectlicense.DetectLicenses("example.com/lib", "v1.0.0", files)
+		var anomalyErr *scanningerrors.AnomalyError
+		if !errors.As(err, &anomalyErr) {
+			t.Fatalf("expected an *AnomalyError, got %#v", err)
+		}
+		expected := &scanningerrors.AnomalyError{
+			Name:     "example.com/lib",
+			Version:  "v1.0.0",
+			Files:    []string{"example.com/lib/NOTICE"},
+			Licenses: []string{detectlicense.MIT.Name},
+			Reason:   "the NOTICE file is really only for the Apache 2.0 and MPL 2.0 licenses; something hokey is going on",
+		}
+		if !reflect.DeepEqual(anomalyErr, expected) {
+			t.Errorf("wrong result:\nexpected: %#v\nreceived: %#v\n", expected, anomalyErr)
+		}
+	})
+}
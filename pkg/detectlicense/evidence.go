@@ -0,0 +1,106 @@
+package detectlicense
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// EvidenceSource is how a license was found.
+type EvidenceSource string
+
+const (
+	// EvidenceLicenseFile is a LICENSE, COPYING, or similar file.
+	EvidenceLicenseFile EvidenceSource = "license-file"
+	// EvidenceSPDXTag is an SPDX-License-Identifier tag in a source file.
+	EvidenceSPDXTag EvidenceSource = "spdx-tag"
+	// EvidenceLicenseHeader is a license header comment in a source file.
+	EvidenceLicenseHeader EvidenceSource = "license-header"
+	// EvidenceCuration is a license file that a human has reviewed the
+	// licenses of (see Curation).
+	EvidenceCuration EvidenceSource = "curation"
+	// EvidenceOverride is a license that was given to us rather than
+	// detected, either because it is in the license database (see
+	// ./database.yaml) or because it came from an --unparsable-packages
+	// file.
+	EvidenceOverride EvidenceSource = "override"
+)
+
+// Evidence is a reason to believe that a package uses a license.
+type Evidence struct {
+	Source EvidenceSource `json:"source"`
+	// File and SHA256 identify the exact bytes that the license was
+	// derived from.  They are empty for hard-coded overrides.
+	File   string `json:"file,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// NewEvidence returns Evidence for a license that was found in the
+// file with the given name and contents.
+func NewEvidence(source EvidenceSource, filename string, body []byte) Evidence {
+	sum := sha256.Sum256(body)
+	return Evidence{
+		Source: source,
+		File:   filename,
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+}
+
+// Detection is the set of licenses that apply to a package, along with
+// the evidence for each of them.
+type Detection map[License][]Evidence
+
+// NewDetection returns a Detection that has the same evidence for each
+// of the licenses.
+func NewDetection(licenses map[License]struct{}, evidence Evidence) Detection {
+	detection := make(Detection, len(licenses))
+	for license := range licenses {
+		detection[license] = []Evidence{evidence}
+	}
+	return detection
+}
+
+// Licenses returns just the licenses, without the evidence.
+func (d Detection) Licenses() map[License]struct{} {
+	licenses := make(map[License]struct{}, len(d))
+	for license := range d {
+		licenses[license] = struct{}{}
+	}
+	return licenses
+}
+
+// Merge adds the evidence from another Detection to this one; evidence
+// that is already present is not duplicated.
+func (d Detection) Merge(other Detection) {
+	for license, evidence := range other {
+		list := d[license]
+		if list == nil {
+			list = []Evidence{}
+		}
+		for _, e := range evidence {
+			if !containsEvidence(list, e) {
+				list = append(list, e)
+			}
+		}
+		sortEvidence(list)
+		d[license] = list
+	}
+}
+
+func containsEvidence(list []Evidence, e Evidence) bool {
+	for _, item := range list {
+		if item == e {
+			return true
+		}
+	}
+	return false
+}
+
+func sortEvidence(list []Evidence) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].File != list[j].File {
+			return list[i].File < list[j].File
+		}
+		return list[i].Source < list[j].Source
+	})
+}
@@ -0,0 +1,59 @@
+package detectlicense_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+)
+
+func TestDetectLicensesWithEvidence(t *testing.T) {
+	files := map[string][]byte{
+		"example.com/lib/lib.go":  []byte(goApacheHeader),
+		"example.com/lib/spdx.go": []byte("// SPDX-License-Identifier: MIT\n\npackage lib\n"),
+	}
+	detection, err := detectlicense.DetectLicensesWithEvidence("example.com/lib", "v1.0.0", files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := detectlicense.Detection{
+		detectlicense.Apache2: {
+			detectlicense.NewEvidence(detectlicense.EvidenceLicenseHeader, "example.com/lib/lib.go", files["example.com/lib/lib.go"]),
+		},
+		detectlicense.MIT: {{
+			Source: detectlicense.EvidenceSPDXTag,
+			File:   "example.com/lib/spdx.go",
+			SHA256: "97c60978ef49b6a07e4bc98af34972526640824f95aa6686f7115bb8ccc58ec5",
+		}},
+	}
+	if !reflect.DeepEqual(detection, expected) {
+		t.Errorf("wrong result:\n"+
+			"expected: %v\n"+
+			"received: %v\n",
+			expected,
+			detection)
+	}
+}
+
+func TestDetectionMerge(t *testing.T) {
+	licenseFile := detectlicense.NewEvidence(detectlicense.EvidenceLicenseFile, "example.com/lib/LICENSE", []byte("license"))
+	spdxTag := detectlicense.NewEvidence(detectlicense.EvidenceSPDXTag, "example.com/lib/a/a.go", []byte("package a"))
+
+	detection := detectlicense.Detection{detectlicense.MIT: {spdxTag, licenseFile}}
+	detection.Merge(detectlicense.Detection{detectlicense.MIT: {licenseFile}})
+	detection.Merge(detectlicense.NewDetection(
+		map[detectlicense.License]struct{}{detectlicense.BSD3: {}},
+		detectlicense.Evidence{Source: detectlicense.EvidenceOverride}))
+
+	expected := detectlicense.Detection{
+		detectlicense.MIT:  {licenseFile, spdxTag},
+		detectlicense.BSD3: {{Source: detectlicense.EvidenceOverride}},
+	}
+	if !reflect.DeepEqual(detection, expected) {
+		t.Errorf("wrong result:\n"+
+			"expected: %v\n"+
+			"received: %v\n",
+			expected,
+			detection)
+	}
+}
@@ -0,0 +1,135 @@
+package detectlicense
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The functions in this file are for suggesting what a human should
+// look at when detection fails; nothing that they return is ever good
+// enough to be used as a license on its own.
+
+// Guess is a best guess at which license a file that IdentifyLicenses
+// couldn't identify is a modified version of.
+type Guess struct {
+	License License
+	SPDXID  string
+	// Similarity is how much of the text is the same as the license's
+	// canonical text, from 0 (nothing) to 1 (all of it).
+	Similarity float64
+}
+
+// minGuessSimilarity is how similar a file has to be to a canonical
+// text to be worth suggesting.
+const minGuessSimilarity = 0.75
+
+var guessWordRE = regexp.MustCompile(`[a-z0-9]+`)
+
+// wordPairs returns how many times each pair of consecutive words
+// appears in a text, ignoring case and punctuation.
+func wordPairs(body []byte) (map[string]int, int) {
+	words := guessWordRE.FindAllString(strings.ToLower(string(body)), -1)
+	pairs := make(map[string]int)
+	for i := 1; i < len(words); i++ {
+		pairs[words[i-1]+" "+words[i]]++
+	}
+	return pairs, len(words) - 1
+}
+
+// similarity is the Sørensen-Dice coefficient of the word pairs of two
+// texts.
+func similarity(a map[string]int, aLen int, b map[string]int, bLen int) float64 {
+	if aLen <= 0 || bLen <= 0 {
+		return 0
+	}
+	common := 0
+	for pair, aCount := range a {
+		bCount := b[pair]
+		if bCount < aCount {
+			common += bCount
+		} else {
+			common += aCount
+		}
+	}
+	return 2 * float64(common) / float64(aLen+bLen)
+}
+
+// GuessLicense returns the license whose canonical text is the most
+// similar to body, if it is similar enough to be worth a human's time.
+// Licenses that share a canonical text (such as GPL-3.0-only and
+// GPL-3.0-or-later) can't be told apart, and the first SPDX identifier
+// is returned.
+func GuessLicense(body []byte) (Guess, bool) {
+	pairs, pairsLen := wordPairs(body)
+	var best Guess
+	for _, text := range getGuessTexts() {
+		if sim := similarity(pairs, pairsLen, text.pairs, text.pairsLen); sim > best.Similarity {
+			best = Guess{License: SpdxIdentifiers[text.spdxID], SPDXID: text.spdxID, Similarity: sim}
+		}
+	}
+	return best, best.Similarity >= minGuessSimilarity
+}
+
+type guessText struct {
+	spdxID   string
+	pairs    map[string]int
+	pairsLen int
+}
+
+//nolint:gochecknoglobals // Would be 'const'.
+var getGuessTexts = sync.OnceValue(func() []guessText {
+	ids := make([]string, 0, len(canonicalTextFiles))
+	for id, license := range SpdxIdentifiers {
+		if _, ok := canonicalTextFiles[license]; ok && SPDXIdentifier(license) == id {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	texts := make([]guessText, 0, len(ids))
+	for _, id := range ids {
+		body, _ := CanonicalText(SpdxIdentifiers[id])
+		pairs, pairsLen := wordPairs(body)
+		texts = append(texts, guessText{spdxID: id, pairs: pairs, pairsLen: pairsLen})
+	}
+	return texts
+})
+
+// SPDXIdentifier returns the SPDX identifier of a license.  If a
+// license has several identifiers (such as a deprecated one), it
+// returns the first of them alphabetically.
+func SPDXIdentifier(license License) string {
+	ret := ""
+	for id, idLicense := range SpdxIdentifiers {
+		if idLicense == license && (ret == "" || id < ret) {
+			ret = id
+		}
+	}
+	return ret
+}
+
+// GuessSPDXIdentifier returns the known SPDX identifier that an unknown
+// one is most likely a variation of: one that only differs in case, or
+// else the longest one that it starts with (for instance "MIT" for
+// "MIT-with-a-twist").
+func GuessSPDXIdentifier(id string) (string, bool) {
+	best := ""
+	for known := range SpdxIdentifiers {
+		if strings.EqualFold(known, id) {
+			return known, true
+		}
+		isPrefix := len(known) < len(id) && strings.EqualFold(known, id[:len(known)]) && !isIDChar(id[len(known)])
+		if isPrefix && (len(known) > len(best) || (len(known) == len(best) && known < best)) {
+			best = known
+		}
+	}
+	return best, best != ""
+}
+
+// isIDChar is whether c would continue, rather than end, a word of an
+// SPDX identifier; so that "MIT" isn't a guess for "MITRE".
+func isIDChar(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c == '.'
+}
@@ -0,0 +1,74 @@
+package detectlicense_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+)
+
+func TestGuessLicense(t *testing.T) {
+	mit, _ := detectlicense.CanonicalText(detectlicense.MIT)
+	apache, _ := detectlicense.CanonicalText(detectlicense.Apache2)
+
+	testcases := map[string]struct {
+		Input        []byte
+		ExpectedOK   bool
+		ExpectedSPDX string
+	}{
+		"canonical": {
+			Input:        apache,
+			ExpectedOK:   true,
+			ExpectedSPDX: "Apache-2.0",
+		},
+		"near-miss": {
+			// An MIT license that somebody has added a clause to, so that
+			// IdentifyLicenses doesn't recognize it.
+			Input: append(bytes.Replace(mit, []byte("furnished to do so"), []byte("furnished to do so (but not on Tuesdays)"), 1),
+				[]byte("\nAlso, be excellent to each other.\n")...),
+			ExpectedOK:   true,
+			ExpectedSPDX: "MIT",
+		},
+		"not-a-license": {
+			Input:      []byte("package intern\n\n// Intern returns s, interned.\nfunc Intern(s string) string {\n\treturn s\n}\n"),
+			ExpectedOK: false,
+		},
+	}
+	for tcName, tcInfo := range testcases {
+		tcInfo := tcInfo
+		t.Run(tcName, func(t *testing.T) {
+			guess, ok := detectlicense.GuessLicense(tcInfo.Input)
+			if ok != tcInfo.ExpectedOK {
+				t.Errorf("wrong result:\nexpected: %v\nreceived: %v (%s, %f)\n", tcInfo.ExpectedOK, ok, guess.SPDXID, guess.Similarity)
+			}
+			if ok && guess.SPDXID != tcInfo.ExpectedSPDX {
+				t.Errorf("wrong result:\nexpected: %s\nreceived: %s\n", tcInfo.ExpectedSPDX, guess.SPDXID)
+			}
+		})
+	}
+}
+
+func TestGuessSPDXIdentifier(t *testing.T) {
+	testcases := map[string]string{
+		"GPL-3.0-or-later-with-some-non-standard-exception": "GPL-3.0-or-later",
+		"apache-2.0":         "Apache-2.0",
+		"MIT WITH something": "MIT",
+		"MITRE":              "",
+		"Frobnicate-1.0":     "",
+	}
+	for input, expected := range testcases {
+		input, expected := input, expected
+		t.Run(input, func(t *testing.T) {
+			received, ok := detectlicense.GuessSPDXIdentifier(input)
+			if ok != (expected != "") || received != expected {
+				t.Errorf("wrong result:\nexpected: %q\nreceived: %q\n", expected, received)
+			}
+		})
+	}
+}
+
+func TestSPDXIdentifier(t *testing.T) {
+	if id := detectlicense.SPDXIdentifier(detectlicense.AFL21); id != "AFL-2.1" {
+		t.Errorf("wrong result:\nexpected: %s\nreceived: %s\n", "AFL-2.1", id)
+	}
+}
@@ -194,13 +194,14 @@ var (
       same "printed page" as the copyright notice for easier
       identification within third-party archives\.`)
 
-	apacheStatement = `(?: *Copyright [^\n]+\n)+` +
-		reWrap(reQuote(`
-   Licensed under the Apache License, Version 2.0 (the "License");
+	// apacheHeader is the part of the "APPENDIX: How to apply the
+	// Apache License to your work" boilerplate that goes in to each
+	// source file.
+	apacheHeader = reWrap(reQuote(`Licensed under the Apache License, Version 2.0 (the "License");
    you may not use this file except in compliance with the License.
    You may obtain a copy of the License at
 
-       `)+`https?://www\.apache\.org/licenses/LICENSE-2\.0`+reQuote(`
+       `) + `https?://www\.apache\.org/licenses/LICENSE-2\.0` + reQuote(`
 
    Unless required by applicable law or agreed to in writing, software
    distributed under the License is distributed on an "AS IS" BASIS,
@@ -208,6 +209,8 @@ var (
    See the License for the specific language governing permissions and
    limitations under the License.`))
 
+	apacheStatement = `(?: *Copyright [^\n]+\n)+\s+` + apacheHeader
+
 	apacheAppendix = `(?:` + apacheAppendixStart + `\s+)?` + apacheStatement
 )
 
@@ -215,3 +218,57 @@ var (
 	reApacheLicense   = regexp.MustCompile(`\s*` + apacheMain + `(?:\s+` + apacheAppendix + `+)?\s*`)
 	reApacheStatement = regexp.MustCompile(`\s*(?:[^\n]{0,15}\n)?\s*` + apacheStatement + `\s*`)
 )
+
+// The Apache License 1.1 is usually shipped as the C comment block from
+// the top of the Apache httpd sources, so tolerate the leading "*"s.
+var reApache11 = regexp.MustCompile(reCaseInsensitive(`\s*` +
+	`(?:/\*\s*)?(?:\*?\s*=+\s*)?(?:\*\s*)?` +
+	starify(``+
+		`(?:The )?Apache(?: Software)? License,? (?:Version )?1\.1`+"\n"+
+		`Copyright \(c\) [^\n]{1,80}\. All rights reserved\.`+"\n"+
+		"\n"+
+		`Redistribution and use in source and binary forms, with or without`+"\n"+
+		`modification, are permitted provided that the following conditions`+"\n"+
+		`are met:`+"\n"+
+		"\n"+
+		`1\. Redistributions of source code must retain the above copyright`+"\n"+
+		`notice, this list of conditions and the following disclaimer\.`+"\n"+
+		"\n"+
+		`2\. Redistributions in binary form must reproduce the above copyright`+"\n"+
+		`notice, this list of conditions and the following disclaimer in`+"\n"+
+		`the documentation and/or other materials provided with the`+"\n"+
+		`distribution\.`+"\n"+
+		"\n"+
+		`3\. The end-user documentation included with the redistribution,`+"\n"+
+		`if any, must include the following acknowledgment:`+"\n"+
+		`"This product includes software developed by [\s\S]{1,200}?"`+"\n"+
+		`Alternately, this acknowledgment may appear in the software itself,`+"\n"+
+		`if and wherever such third-party acknowledgments normally appear\.`+"\n"+
+		"\n"+
+		`4\. The names? [\s\S]{1,200}? must`+"\n"+
+		`not be used to endorse or promote products derived from this`+"\n"+
+		`software without prior written permission\.[\s\S]{0,200}?`+"\n"+
+		"\n"+
+		`5\. Products derived from this software may not be called [\s\S]{1,200}?,?`+"\n"+
+		`nor may [\s\S]{1,200}? appear in their name, without prior written`+"\n"+
+		`permission of [\s\S]{1,200}?\.`+"\n"+
+		"\n"+
+		`THIS SOFTWARE IS PROVIDED (?:"|''|`+"``"+`)AS IS(?:"|'') AND ANY EXPRESSED OR IMPLIED`+"\n"+
+		`WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES`+"\n"+
+		`OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE`+"\n"+
+		`DISCLAIMED\. IN NO EVENT SHALL [\s\S]{1,200}? OR`+"\n"+
+		`ITS CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,`+"\n"+
+		`SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES \(INCLUDING, BUT NOT`+"\n"+
+		`LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF`+"\n"+
+		`USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION\) HOWEVER CAUSED AND`+"\n"+
+		`ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,`+"\n"+
+		`OR TORT \(INCLUDING NEGLIGENCE OR OTHERWISE\) ARISING IN ANY WAY OUT`+"\n"+
+		`OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF`+"\n"+
+		`SUCH DAMAGE\.`) +
+	`(?:(?:\s+\*)*\s+=+)?` +
+	// Optional trailer about the ASF and NCSA.
+	`(?:(?:\s+\*)*\s+` +
+	starify(`This software consists of voluntary contributions made by many`+"\n"+
+		`individuals on behalf of [\s\S]{1,800}?\.`) +
+	`)?` +
+	`(?:\s*\*/)?\s*`))
@@ -23,6 +23,14 @@ const (
 		` (?:3?[*.-] )?(?:Neither (?:the )?.{1,80} nor the names of its contributors may|(?:My name, .{1,80}|The names of its contributors) may not)` + "\n" +
 		`      be used to endorse or promote products derived from this software` + "\n" +
 		`      without specific prior written permission\.` + "\n"
+	bsdClauseAdvertising = `` +
+		` (?:3?[*.-] )?All advertising materials mentioning features or use of this software` + "\n" +
+		`      must display the following acknowledge?ment:` + "\n" +
+		`      [\s\S]{1,200}?\.` + "\n"
+	bsdClause4 = `` +
+		` (?:4?[*.-] )?(?:Neither (?:the )?.{1,80} nor the names of its contributors may|(?:My name, .{1,80}|The names of its contributors) may not)` + "\n" +
+		`      be used to endorse or promote products derived from this software` + "\n" +
+		`      without specific prior written permission\.` + "\n"
 	bsdSuffix = `` +
 		`THIS SOFTWARE IS PROVIDED BY .{1,80} AND CONTRIBUTORS` + "\n" +
 		`(?:"|`+"``"+`)AS IS(?:"|'') AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT` + "\n" +
@@ -46,6 +54,18 @@ var (
 			bsdClause2+
 			bsdClause3+
 			bsdSuffix)))
+	reBSD4 = regexp.MustCompile(reCaseInsensitive(`` +
+		`(?:BSD 4-Clause License\n)?\s*` +
+		// Copyright lines, which in the old Berkeley sources are
+		// continued on indented lines.
+		`(?:Copyright [^\n]*(?:\n[ \t]+[^\n]*)*(?:\s+All rights reserved\.)? *\n)+\s*` +
+		reWrap(``+
+			bsdPrefix+
+			bsdClause1+
+			bsdClause2+
+			bsdClauseAdvertising+
+			bsdClause4+
+			bsdSuffix)))
 	reBSD2 = regexp.MustCompile(reCaseInsensitive(`` +
 		bsdHeader +
 		reWrap(``+
@@ -0,0 +1,32 @@
+package detectlicense
+
+import (
+	"regexp"
+)
+
+// The Commons Clause is not a license of its own, but a rider that gets
+// put on top of an open source license (usually Apache 2.0) to forbid
+// selling the software; so it's normally found in the same file as the
+// license that it modifies.
+var reCommonsClause = regexp.MustCompile(reSmartQuotes(`\s*` +
+	`(?:"?Commons Clause"? (?:License Condition v1\.0|Restriction)\s*)?` +
+	reWrap(``+
+		`The Software is provided to you by the Licensor under the License, as defined`+"\n"+
+		`below, subject to the following condition\.`+"\n"+
+		"\n"+
+		`Without limiting other conditions in the License, the grant of rights under the`+"\n"+
+		`License will not include, and the License does not grant to you, the right to`+"\n"+
+		`Sell the Software\.`+"\n"+
+		"\n"+
+		`For purposes of the foregoing, "Sell" means practicing any or all of the rights`+"\n"+
+		`granted to you under the License to provide to third parties, for a fee or other`+"\n"+
+		`consideration`+
+		`(?: \(including without limitation fees for hosting or consulting/\s*support`+"\n"+
+		`services related to the Software\), a product or service whose value derives,`+"\n"+
+		`entirely or substantially, from the functionality of the Software`+
+		`|, a product or service that consists, entirely or substantially, of the`+"\n"+
+		`Software or the functionality of the Software)\.`+"\n"+
+		`Any license notice or attribution required by the License must also include`+"\n"+
+		`this Commons Cl?ause License Condition notice\.`) +
+	// The parameter block, as filled in by the licensor.
+	`(?:\s*(?:Software|License|Licensor): [^\n]*)*` + `\s*`))
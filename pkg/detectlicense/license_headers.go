@@ -0,0 +1,159 @@
+package detectlicense
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Most licenses come with boilerplate that you are supposed to put in
+// a comment at the top of each source file ("How to apply these terms
+// to your work").  Plenty of source files have that header but no
+// SPDX-License-Identifier tag, so we look for those headers too.
+
+//nolint:gochecknoglobals // Would be 'const'.
+var headerCommentExtensions = map[string]struct{}{
+	// Go
+	".go": {},
+	// C and C++ (including the sources vendored in to cgo packages)
+	".c":   {},
+	".h":   {},
+	".cc":  {},
+	".cpp": {},
+	".hpp": {},
+	// assembly (Go's assembler and the C preprocessor both take C
+	// comments)
+	".s": {},
+	".S": {},
+	// JavaScript
+	".js":  {},
+	".mjs": {},
+	".cjs": {},
+}
+
+// gnuHeader returns a regex for the "This program is free software"
+// header of the GNU licenses.
+func gnuHeader(licenseName, version string, orLater bool) string {
+	versionClause := `version ` + reQuote(version) + `(?: of the License)?\.`
+	if orLater {
+		versionClause = `either version ` + reQuote(version) + `(?: of the License)?,? or \(at your option\) any later version\.`
+	}
+	return reWrap(`(?:This (?:program|library|file|software)|\S+(?: \S+){0,2}) is free software[;:,] ` +
+		`you can redistribute it and/or modify it under the terms of the GNU ` + licenseName + ` ` +
+		`as published by the Free Software Foundation[;,]? ` + versionClause)
+}
+
+//nolint:gochecknoglobals // Would be 'const'.
+var licenseHeaders = []struct {
+	re      *regexp.Regexp
+	license License
+}{
+	// Headers: these only have to appear somewhere in the comment.
+	{regexp.MustCompile(reSmartQuotes(apacheHeader)), Apache2},
+	{regexp.MustCompile(reWrap(reQuote(`This Source Code Form is subject to the terms of the Mozilla Public
+  License, v. 2.0. If a copy of the MPL was not distributed with this
+  file, `) + `[Yy]ou can obtain one at https?://mozilla\.org/MPL/2\.0/?`)), MPL2},
+	{regexp.MustCompile(gnuHeader(`General Public License`, `2`, false)), GPL2Only},
+	{regexp.MustCompile(gnuHeader(`General Public License`, `2`, true)), GPL2OrLater},
+	{regexp.MustCompile(gnuHeader(`General Public License`, `3`, false)), GPL3Only},
+	{regexp.MustCompile(gnuHeader(`General Public License`, `3`, true)), GPL3OrLater},
+	{regexp.MustCompile(gnuHeader(`Library General Public License`, `2`, false)), LGPL2Only},
+	{regexp.MustCompile(gnuHeader(`Library General Public License`, `2`, true)), LGPL2OrLater},
+	{regexp.MustCompile(gnuHeader(`Lesser General Public License`, `2.1`, false)), LGPL21Only},
+	{regexp.MustCompile(gnuHeader(`Lesser General Public License`, `2.1`, true)), LGPL21OrLater},
+	{regexp.MustCompile(gnuHeader(`Lesser General Public License`, `3`, false)), LGPL3Only},
+	{regexp.MustCompile(gnuHeader(`Lesser General Public License`, `3`, true)), LGPL3OrLater},
+	{regexp.MustCompile(gnuHeader(`Affero General Public License`, `3`, false)), AGPL3Only},
+	{regexp.MustCompile(gnuHeader(`Affero General Public License`, `3`, true)), AGPL3OrLater},
+
+	// Short permissive licenses get pasted in to the comment in
+	// full; the comment has to be exactly the license.
+	{regexp.MustCompile(`\A` + reMIT.String() + `\z`), MIT},
+	{regexp.MustCompile(`\A` + reBSD2.String() + `\z`), BSD2},
+	{regexp.MustCompile(`\A` + reBSD3.String() + `\z`), BSD3},
+	{regexp.MustCompile(`\A` + reISC.String() + `\z`), ISC},
+}
+
+// IdentifyLicenseHeaders takes the contents of a source-file and looks
+// for license headers in the comments at the top of it.  Files in a
+// language that we don't know the comment syntax of never have any
+// headers.
+func IdentifyLicenseHeaders(filename string, body []byte) map[License]struct{} {
+	licenses := make(map[License]struct{})
+	if _, ok := headerCommentExtensions[filepath.Ext(filename)]; !ok {
+		return licenses
+	}
+	for _, comment := range leadingComments(string(body)) {
+		for _, header := range licenseHeaders {
+			if header.re.MatchString(comment) {
+				licenses[header.license] = struct{}{}
+			}
+		}
+	}
+	return licenses
+}
+
+// leadingComments returns the comments before the first line of code
+// in a file with C-style comments, with the comment markers stripped.
+// Each "/* */" comment and each run of "//" lines is returned
+// separately.
+func leadingComments(body string) []string {
+	var comments []string
+	var comment []string
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(comment, "\n")); text != "" {
+			comments = append(comments, text)
+		}
+		comment = nil
+	}
+
+	inBlock := false
+	for i, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			if end := strings.Index(line, "*/"); end >= 0 {
+				if strings.TrimSpace(line[end+len("*/"):]) != "" {
+					// Code after the end of the comment.
+					flush()
+					return comments
+				}
+				line = line[:end]
+				inBlock = false
+			}
+			comment = append(comment, strings.TrimSpace(strings.TrimLeft(line, "*")))
+			if !inBlock {
+				flush()
+			}
+		case line == "":
+			flush()
+		case i == 0 && strings.HasPrefix(line, "#!"):
+			// A script's interpreter line.
+		case strings.HasPrefix(line, "//go:") || strings.HasPrefix(line, "// +build"):
+			// Go directives aren't part of the text of a comment.
+			flush()
+		case strings.HasPrefix(line, "//"):
+			comment = append(comment, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+		case strings.HasPrefix(line, "/*"):
+			flush()
+			line = strings.TrimPrefix(line, "/*")
+			if end := strings.Index(line, "*/"); end >= 0 {
+				if strings.TrimSpace(line[end+len("*/"):]) != "" {
+					return comments
+				}
+				line = line[:end]
+			} else {
+				inBlock = true
+			}
+			comment = append(comment, strings.TrimSpace(strings.TrimLeft(line, "*")))
+			if !inBlock {
+				flush()
+			}
+		default:
+			flush()
+			return comments
+		}
+	}
+	flush()
+	return comments
+}
@@ -0,0 +1,191 @@
+package detectlicense_test
+
+import (
+	"testing"
+
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+)
+
+const (
+	goApacheHeader = `// Copyright 2019 Example Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+// Package example does things.
+package example
+`
+
+	cGPL2Header = `/*
+ * example.c - does things
+ *
+ * Copyright (C) 2004 Example Authors
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ */
+
+#include <stdio.h>
+`
+
+	cLGPL21Header = `/* Copyright (C) 2011 Example Authors
+
+   This library is free software; you can redistribute it and/or
+   modify it under the terms of the GNU Lesser General Public
+   License as published by the Free Software Foundation; either
+   version 2.1 of the License, or (at your option) any later version. */
+#include "example.h"
+`
+
+	asmMPLHeader = `// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+#include "textflag.h"
+
+TEXT ·add(SB),NOSPLIT,$0
+`
+
+	jsMITHeader = `#!/usr/bin/env node
+/**
+ * Copyright (c) 2015 Example Authors
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be
+ * included in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+ * NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+ * LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+ * OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+ * WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+'use strict';
+`
+
+	goHeaderAfterCode = `package example
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+`
+)
+
+func TestIdentifyLicenseHeaders(t *testing.T) {
+	testcases := map[string]struct {
+		filename string
+		body     string
+		expected map[detectlicense.License]struct{}
+	}{
+		"go-apache": {
+			filename: "example.go",
+			body:     goApacheHeader,
+			expected: map[detectlicense.License]struct{}{detectlicense.Apache2: {}},
+		},
+		"c-gpl2-or-later": {
+			filename: "example.c",
+			body:     cGPL2Header,
+			expected: map[detectlicense.License]struct{}{detectlicense.GPL2OrLater: {}},
+		},
+		"c-lgpl21-or-later": {
+			filename: "example.h",
+			body:     cLGPL21Header,
+			expected: map[detectlicense.License]struct{}{detectlicense.LGPL21OrLater: {}},
+		},
+		"asm-mpl2": {
+			filename: "add_amd64.s",
+			body:     asmMPLHeader,
+			expected: map[detectlicense.License]struct{}{detectlicense.MPL2: {}},
+		},
+		"js-mit": {
+			filename: "index.js",
+			body:     jsMITHeader,
+			expected: map[detectlicense.License]struct{}{detectlicense.MIT: {}},
+		},
+		"header-after-code": {
+			filename: "example.go",
+			body:     goHeaderAfterCode,
+			expected: map[detectlicense.License]struct{}{},
+		},
+		"unknown-comment-syntax": {
+			filename: "example.py",
+			body:     goApacheHeader,
+			expected: map[detectlicense.License]struct{}{},
+		},
+	}
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			licenses := detectlicense.IdentifyLicenseHeaders(tc.filename, []byte(tc.body))
+			if !licenseListEqual(licenses, tc.expected) {
+				t.Errorf("wrong result:\n"+
+					"expected: %s\n"+
+					"received: %s\n",
+					fmtLicenses(tc.expected),
+					fmtLicenses(licenses))
+			}
+		})
+	}
+}
+
+func TestDetectLicensesHeadersOnly(t *testing.T) {
+	files := map[string][]byte{
+		"example.com/cgolib/lib.go":       []byte(goApacheHeader),
+		"example.com/cgolib/vendor/lib.c": []byte(cGPL2Header),
+	}
+	licenses, err := detectlicense.DetectLicenses("example.com/cgolib", "v1.0.0", files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[detectlicense.License]struct{}{
+		detectlicense.Apache2:     {},
+		detectlicense.GPL2OrLater: {},
+	}
+	if !licenseListEqual(licenses, expected) {
+		t.Errorf("wrong result:\n"+
+			"expected: %s\n"+
+			"received: %s\n",
+			fmtLicenses(expected),
+			fmtLicenses(licenses))
+	}
+
+	files["example.com/cgolib/other.go"] = []byte("package cgolib\n")
+	if _, err := detectlicense.DetectLicenses("example.com/cgolib", "v1.0.0", files); err == nil {
+		t.Error("expected an error for a source file with neither an SPDX tag nor a license header")
+	}
+}
@@ -40,3 +40,57 @@ var (
 		// Allow a footer.
 		`(` + reQuote(`<http://www.opensource.org/licenses/mit-license.php>`) + `\s*)?`))
 )
+
+var (
+	reMIT0 = regexp.MustCompile(reCaseInsensitive(`\s*` +
+		`(?:(?:` +
+		`(?:The )?MIT(?: No Attribution|-0) License|MIT No Attribution|` +
+		`(?:Portions )?Copyright [^\n]*` +
+		`)\s*\n\s*)*` +
+		reWrap(``+
+			`Permission is hereby granted, free of charge, to any person obtaining a copy`+"\n"+
+			`of this software and associated documentation files \(the ["“']Software["”']\), to deal`+"\n"+
+			`in the Software without restriction, including without limitation the rights`+"\n"+
+			`to use, copy, modify, merge, publish, distribute, sublicense, and/or sell`+"\n"+
+			`copies of the Software, and to permit persons to whom the Software is`+"\n"+
+			`furnished to do so\.`+"\n"+
+			"\n"+
+			`THE SOFTWARE IS PROVIDED ["“']AS IS["”'], WITHOUT WARRANTY OF ANY KIND, EXPRESS OR`+"\n"+
+			`IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,`+"\n"+
+			`FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT\. IN NO EVENT SHALL THE`+"\n"+
+			`AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER`+"\n"+
+			`LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,`+"\n"+
+			`OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE`+"\n"+
+			`SOFTWARE\.\s*`)))
+
+	// The X11 license is the MIT license with the X Consortium named in
+	// the disclaimer plus a clause forbidding use of that name in
+	// advertising.
+	reX11 = regexp.MustCompile(reCaseInsensitive(`\s*` +
+		`(?:(?:` +
+		`(?:The )?X11 License|` +
+		`(?:Portions )?Copyright [^\n]*` +
+		`)\s*\n\s*)*` +
+		reWrap(``+
+			`Permission is hereby granted, free of charge, to any person obtaining a copy`+"\n"+
+			`of this software and associated documentation files \(the ["“']Software["”']\), to deal`+"\n"+
+			`in the Software without restriction, including without limitation the rights`+"\n"+
+			`to use, copy, modify, merge, publish, distribute, sublicense, and/or sell`+"\n"+
+			`copies of the Software, and to permit persons to whom the Software is`+"\n"+
+			`furnished to do so, subject to the following conditions:`+"\n"+
+			"\n"+
+			`The above copyright notice and this permission notice shall be included in`+"\n"+
+			`all copies or substantial portions of the Software\.`+"\n"+
+			"\n"+
+			`THE SOFTWARE IS PROVIDED ["“']AS IS["”'], WITHOUT WARRANTY OF ANY KIND, EXPRESS OR`+"\n"+
+			`IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,`+"\n"+
+			`FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT\. IN NO EVENT SHALL`+"\n"+
+			`[\s\S]{1,80}? BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER`+"\n"+
+			`IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN`+"\n"+
+			`CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE\.`+"\n"+
+			"\n"+
+			`Except as contained in this notice, the name of [\s\S]{1,80}? shall not be`+"\n"+
+			`used in advertising or otherwise to promote the sale, use or other dealings`+"\n"+
+			`in this Software without prior written authorization from [\s\S]{1,80}?\.\s*`) +
+		`(?:X Window System is a trademark of X Consortium, Inc\.\s*)?`))
+)
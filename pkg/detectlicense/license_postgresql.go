@@ -0,0 +1,34 @@
+package detectlicense
+
+import (
+	"regexp"
+)
+
+var (
+	rePostgreSQL = regexp.MustCompile(reCaseInsensitive(`\s*` +
+		// Any number of license-name, project-name, or copyright lines
+		`(?:(?:` +
+		`(?:The )?PostgreSQL License|` +
+		`PostgreSQL Database Management System|` +
+		`\(formerly known as Postgres, then as Postgres95\)|` +
+		`[^\n]{0,40}` + reQuote(`is released under the PostgreSQL License, a liberal Open Source license, similar to the BSD or MIT licenses.`) + `|` +
+		`(?:Portions )?Copyright [^\n]*(?:\n[^\n]*Group)?` +
+		`)\s*\n\s*)*` +
+		reWrap(``+
+			`Permission to use, copy, modify, and distribute this software and its`+"\n"+
+			`documentation for any purpose, without fee, and without a written agreement`+"\n"+
+			`is hereby granted, provided that the above copyright notice and this`+"\n"+
+			`paragraph and the following two paragraphs appear in all copies\.`+"\n"+
+			"\n"+
+			`IN NO EVENT SHALL [\s\S]{1,80}? BE LIABLE TO ANY PARTY FOR`+"\n"+
+			`DIRECT, INDIRECT, SPECIAL, INCIDENTAL, OR CONSEQUENTIAL DAMAGES, INCLUDING`+"\n"+
+			`LOST PROFITS, ARISING OUT OF THE USE OF THIS SOFTWARE AND ITS`+"\n"+
+			`DOCUMENTATION, EVEN IF [\s\S]{1,80}? HAS BEEN ADVISED OF THE`+"\n"+
+			`POSSIBILITY OF SUCH DAMAGE\.`+"\n"+
+			"\n"+
+			`[\s\S]{1,80}? SPECIFICALLY DISCLAIMS ANY WARRANTIES,`+"\n"+
+			`INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY`+"\n"+
+			`AND FITNESS FOR A PARTICULAR PURPOSE\. THE SOFTWARE PROVIDED HEREUNDER IS`+"\n"+
+			`ON AN ["“]AS IS["”] BASIS, AND [\s\S]{1,80}? HAS NO OBLIGATIONS TO`+"\n"+
+			`PROVIDE MAINTENANCE, SUPPORT, UPDATES, ENHANCEMENTS, OR MODIFICATIONS\.\s*`)))
+)
@@ -0,0 +1,72 @@
+package detectlicense
+
+import (
+	"embed"
+)
+
+// texts/ has the canonical text of the licenses that dependencies are
+// allowed to use, taken from the SPDX license list.  They're for when a
+// package doesn't have a license file of its own to ship (the license
+// was detected from SPDX tags or license headers, or was overridden).
+//
+//go:embed texts/*.txt
+var canonicalTexts embed.FS
+
+//nolint:gochecknoglobals // Would be 'const'.
+var canonicalTextFiles = map[License]string{
+	AFL21:         "AFL-2.1.txt",
+	Apache11:      "Apache-1.1.txt",
+	Apache2:       "Apache-2.0.txt",
+	Artistic20:    "Artistic-2.0.txt",
+	BSD2:          "BSD-2-Clause.txt",
+	BSD3:          "BSD-3-Clause.txt",
+	BSD4:          "BSD-4-Clause.txt",
+	BSL10:         "BSL-1.0.txt",
+	CcBy30:        "CC-BY-3.0.txt",
+	CcBy40:        "CC-BY-4.0.txt",
+	CcBySa40:      "CC-BY-SA-4.0.txt",
+	Cc010:         "CC0-1.0.txt",
+	CDDL10:        "CDDL-1.0.txt",
+	CDDL11:        "CDDL-1.1.txt",
+	EPL10:         "EPL-1.0.txt",
+	EPL20:         "EPL-2.0.txt",
+	GPL1Only:      "GPL-1.0.txt",
+	GPL1OrLater:   "GPL-1.0.txt",
+	GPL2Only:      "GPL-2.0.txt",
+	GPL2OrLater:   "GPL-2.0.txt",
+	GPL3Only:      "GPL-3.0.txt",
+	GPL3OrLater:   "GPL-3.0.txt",
+	ISC:           "ISC.txt",
+	LGPL2Only:     "LGPL-2.0.txt",
+	LGPL2OrLater:  "LGPL-2.0.txt",
+	LGPL21Only:    "LGPL-2.1.txt",
+	LGPL21OrLater: "LGPL-2.1.txt",
+	LGPL3Only:     "LGPL-3.0.txt",
+	LGPL3OrLater:  "LGPL-3.0.txt",
+	MIT:           "MIT.txt",
+	MIT0:          "MIT-0.txt",
+	MPL11:         "MPL-1.1.txt",
+	MPL2:          "MPL-2.0.txt",
+	OFL11:         "OFL-1.1.txt",
+	PostgreSQL:    "PostgreSQL.txt",
+	Python20:      "Python-2.0.txt",
+	Unicode2015:   "Unicode-DFS-2015.txt",
+	Unlicense:     "Unlicense.txt",
+	WTFPL:         "WTFPL.txt",
+	X11:           "X11.txt",
+	Zlib:          "Zlib.txt",
+}
+
+// CanonicalText returns the canonical text of a license, if we have
+// it.
+func CanonicalText(license License) ([]byte, bool) {
+	filename, ok := canonicalTextFiles[license]
+	if !ok {
+		return nil, false
+	}
+	text, err := canonicalTexts.ReadFile("texts/" + filename)
+	if err != nil {
+		panic(err)
+	}
+	return text, true
+}
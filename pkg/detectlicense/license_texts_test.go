@@ -0,0 +1,66 @@
+package detectlicense_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+)
+
+func TestCanonicalText(t *testing.T) {
+	// The canonical texts should be recognized as the license that they
+	// are the text of.
+	testcases := []detectlicense.License{
+		detectlicense.Apache11,
+		detectlicense.Apache2,
+		detectlicense.Artistic20,
+		detectlicense.BSD2,
+		detectlicense.BSD3,
+		detectlicense.BSD4,
+		detectlicense.BSL10,
+		detectlicense.CcBySa40,
+		detectlicense.Cc010,
+		detectlicense.CDDL10,
+		detectlicense.CDDL11,
+		detectlicense.EPL10,
+		detectlicense.EPL20,
+		detectlicense.GPL2Only,
+		detectlicense.GPL3Only,
+		detectlicense.ISC,
+		detectlicense.LGPL2Only,
+		detectlicense.LGPL21Only,
+		detectlicense.LGPL3Only,
+		detectlicense.MIT,
+		detectlicense.MIT0,
+		detectlicense.MPL2,
+		detectlicense.PostgreSQL,
+		detectlicense.X11,
+		detectlicense.Zlib,
+	}
+	for _, license := range testcases {
+		license := license
+		t.Run(license.Name, func(t *testing.T) {
+			text, ok := detectlicense.CanonicalText(license)
+			if !ok {
+				t.Fatal("no canonical text")
+			}
+			expected := map[detectlicense.License]struct{}{license: {}}
+			received := detectlicense.IdentifyLicenses(text)
+			if !licenseListEqual(received, expected) {
+				t.Errorf("wrong result:\n"+
+					"expected: %s\n"+
+					"received: %s\n",
+					fmtLicenses(expected),
+					fmtLicenses(received))
+			}
+			// A disclaimer is a paragraph of its own.
+			if i := bytes.Index(text, []byte("\nTHIS SOFTWARE IS PROVIDED")); i > 0 && !bytes.HasSuffix(text[:i+1], []byte("\n\n")) {
+				t.Error("no blank line before the disclaimer")
+			}
+		})
+	}
+
+	if _, ok := detectlicense.CanonicalText(detectlicense.AmbassadorProprietary); ok {
+		t.Error("proprietary licenses should not have a canonical text")
+	}
+}
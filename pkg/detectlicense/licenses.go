@@ -6,66 +6,105 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
 )
 
 type LicenseRestriction int
 
 const (
 	Forbidden LicenseRestriction = iota
-	AmbassadorServers
+	// InternalUseOnly licenses are only allowed for software that runs
+	// on the organization's own servers, rather than on customer
+	// machines.
+	InternalUseOnly
 	Unrestricted
 )
 
+// Deprecated: Use InternalUseOnly.
+const AmbassadorServers = InternalUseOnly
+
 type License struct {
-	Name         string
-	NoticeFile   bool               // are NOTICE files "a thing" for this license?
-	WeakCopyleft bool               // requires that library to be open-source
-	URL          string             // Location of the license description
-	Restriction  LicenseRestriction // Where is this license allowed
+	Name            string
+	Proprietary     bool               // the organization's own software; see Profile.ProprietaryLicense
+	NoticeFile      bool               // are NOTICE files "a thing" for this license?
+	WeakCopyleft    bool               // requires that library to be open-source
+	SourceAvailable bool               // the source is public, but it is not an open-source license
+	URL             string             // Location of the license description
+	Restriction     LicenseRestriction // Where is this license allowed
 }
 
 //nolint:gochecknoglobals // Would be 'const'.
 var (
-	AmbassadorProprietary = License{Name: "proprietary Ambassador software"}
+	// FirstPartyProprietary is the license of the organization's own
+	// proprietary software (see ProprietarySoftware), if the Profile
+	// doesn't name it.
+	FirstPartyProprietary = License{Name: "proprietary first-party software", Proprietary: true}
+	// AmbassadorProprietary is the ProprietaryLicense of the
+	// "ambassador" Profile.
+	//
+	// Deprecated: Use Profile.ProprietaryLicense.
+	AmbassadorProprietary = License{Name: "proprietary Ambassador software", Proprietary: true}
 	ZeroBSD               = License{Name: "BSD Zero Clause License",
 		URL: "https://spdx.org/licenses/0BSD.html", Restriction: Unrestricted}
 	Apache2 = License{Name: "Apache License 2.0", NoticeFile: true,
 		URL: "https://opensource.org/licenses/Apache-2.0", Restriction: Unrestricted}
+	Apache11 = License{Name: "Apache License 1.1", URL: "https://spdx.org/licenses/Apache-1.1.html",
+		Restriction: Unrestricted}
 	AFL21 = License{Name: "Academic Free License v2.1", URL: "https://spdx.org/licenses/AFL-2.1.html",
 		Restriction: Unrestricted}
 	AGPL1Only    = License{Name: "Affero General Public License v1.0 only", Restriction: Forbidden}
 	AGPL1OrLater = License{Name: "Affero General Public License v1.0 or later", Restriction: Forbidden}
 	AGPL3Only    = License{Name: "GNU Affero General Public License v3.0 only", Restriction: Forbidden}
 	AGPL3OrLater = License{Name: "GNU Affero General Public License v3.0 or later", Restriction: Forbidden}
-	BSD1         = License{Name: "1-clause BSD license", URL: "https://opensource.org/licenses/BSD-1-Clause",
+	Artistic20   = License{Name: "Artistic License 2.0", URL: "https://spdx.org/licenses/Artistic-2.0.html",
+		Restriction: Unrestricted}
+	BSD1 = License{Name: "1-clause BSD license", URL: "https://opensource.org/licenses/BSD-1-Clause",
 		Restriction: Unrestricted}
 	BSD2 = License{Name: "2-clause BSD license", URL: "https://opensource.org/licenses/BSD-2-Clause",
 		Restriction: Unrestricted}
 	BSD3 = License{Name: "3-clause BSD license", URL: "https://opensource.org/licenses/BSD-3-Clause",
 		Restriction: Unrestricted}
+	BSD4 = License{Name: "4-clause BSD license", URL: "https://spdx.org/licenses/BSD-4-Clause.html",
+		Restriction: Unrestricted}
+	BUSL11 = License{Name: "Business Source License 1.1", SourceAvailable: true,
+		URL: "https://spdx.org/licenses/BUSL-1.1.html", Restriction: Forbidden}
+	BSL10 = License{Name: "Boost Software License 1.0", URL: "https://spdx.org/licenses/BSL-1.0.html",
+		Restriction: Unrestricted}
 	CcBy30 = License{Name: "Creative Commons Attribution 3.0 Unported",
-		URL: "https://spdx.org/licenses/CC-BY-3.0.html", Restriction: AmbassadorServers}
+		URL: "https://spdx.org/licenses/CC-BY-3.0.html", Restriction: InternalUseOnly}
 	CcBy40 = License{Name: "Creative Commons Attribution 4.0 International",
-		URL: "https://spdx.org/licenses/CC-BY-4.0.html", Restriction: AmbassadorServers}
+		URL: "https://spdx.org/licenses/CC-BY-4.0.html", Restriction: InternalUseOnly}
 	CcBySa40 = License{Name: "Creative Commons Attribution Share Alike 4.0 International",
-		URL: "https://spdx.org/licenses/CC-BY-SA-4.0.html", Restriction: AmbassadorServers}
+		URL: "https://spdx.org/licenses/CC-BY-SA-4.0.html", Restriction: InternalUseOnly}
 	Cc010 = License{Name: "Creative Commons Zero v1.0 Universal",
 		URL: "https://spdx.org/licenses/CC0-1.0.html", Restriction: Unrestricted}
-	EPL10 = License{Name: "Eclipse Public License 1.0", URL: "https://spdx.org/licenses/EPL-1.0.html",
-		Restriction: Unrestricted}
+	CommonsClause = License{Name: "Commons Clause License Condition v1.0", SourceAvailable: true,
+		URL: "https://commonsclause.com/", Restriction: Forbidden}
+	CDDL10 = License{Name: "Common Development and Distribution License 1.0", WeakCopyleft: true,
+		URL: "https://spdx.org/licenses/CDDL-1.0.html", Restriction: Unrestricted}
+	CDDL11 = License{Name: "Common Development and Distribution License 1.1", WeakCopyleft: true,
+		URL: "https://spdx.org/licenses/CDDL-1.1.html", Restriction: Unrestricted}
+	Elastic20 = License{Name: "Elastic License 2.0", SourceAvailable: true,
+		URL: "https://spdx.org/licenses/Elastic-2.0.html", Restriction: Forbidden}
+	EPL10 = License{Name: "Eclipse Public License 1.0", WeakCopyleft: true,
+		URL: "https://spdx.org/licenses/EPL-1.0.html", Restriction: Unrestricted}
+	EPL20 = License{Name: "Eclipse Public License 2.0", WeakCopyleft: true,
+		URL: "https://spdx.org/licenses/EPL-2.0.html", Restriction: Unrestricted}
 	GPL1Only = License{Name: "GNU General Public License v1.0 only",
-		URL: "https://spdx.org/licenses/GPL-1.0-only.html", Restriction: AmbassadorServers}
+		URL: "https://spdx.org/licenses/GPL-1.0-only.html", Restriction: InternalUseOnly}
 	GPL1OrLater = License{Name: "GNU General Public License v1.0 or later",
-		URL: "https://spdx.org/licenses/GPL-1.0-or-later.html", Restriction: AmbassadorServers}
+		URL: "https://spdx.org/licenses/GPL-1.0-or-later.html", Restriction: InternalUseOnly}
 	GPL2Only = License{Name: "GNU General Public License v2.0 only",
-		URL: "https://spdx.org/licenses/GPL-2.0-only.html", Restriction: AmbassadorServers}
+		URL: "https://spdx.org/licenses/GPL-2.0-only.html", Restriction: InternalUseOnly}
 	GPL2OrLater = License{Name: "GNU General Public License v2.0 or later",
-		URL: "https://spdx.org/licenses/GPL-2.0-or-later.html", Restriction: AmbassadorServers}
+		URL: "https://spdx.org/licenses/GPL-2.0-or-later.html", Restriction: InternalUseOnly}
 	GPL3Only = License{Name: "GNU General Public License v3.0 only",
-		URL: "https://spdx.org/licenses/GPL-3.0.html", Restriction: AmbassadorServers}
+		URL: "https://spdx.org/licenses/GPL-3.0.html", Restriction: InternalUseOnly}
 	GPL3OrLater = License{Name: "GNU General Public License v3.0 or later",
-		URL: "https://spdx.org/licenses/GPL-3.0-or-later.html", Restriction: AmbassadorServers}
+		URL: "https://spdx.org/licenses/GPL-3.0-or-later.html", Restriction: InternalUseOnly}
 	ISC       = License{Name: "ISC license", URL: "https://opensource.org/licenses/ISC", Restriction: Unrestricted}
 	LGPL2Only = License{Name: "GNU Library General Public License v2 only", WeakCopyleft: true,
 		Restriction: Unrestricted}
@@ -80,6 +119,7 @@ var (
 	LGPL3OrLater = License{Name: "GNU Lesser General Public License v3.0 or later", WeakCopyleft: true,
 		Restriction: Unrestricted}
 	MIT   = License{Name: "MIT license", URL: "https://opensource.org/licenses/MIT", Restriction: Unrestricted}
+	MIT0  = License{Name: "MIT No Attribution", URL: "https://spdx.org/licenses/MIT-0.html", Restriction: Unrestricted}
 	MPL11 = License{Name: "Mozilla Public License 1.1", NoticeFile: true,
 		WeakCopyleft: true, URL: "https://spdx.org/licenses/MPL-1.1.html", Restriction: Unrestricted}
 	MPL2 = License{Name: "Mozilla Public License 2.0", NoticeFile: true,
@@ -88,10 +128,18 @@ var (
 		Restriction: Unrestricted}
 	OFL11 = License{Name: "SIL Open Font License 1.1", URL: "https://spdx.org/licenses/OFL-1.1.html",
 		Restriction: Unrestricted}
+	PolyFormNoncommercial100 = License{Name: "PolyForm Noncommercial License 1.0.0", SourceAvailable: true,
+		URL: "https://spdx.org/licenses/PolyForm-Noncommercial-1.0.0.html", Restriction: Forbidden}
+	PolyFormSmallBusiness100 = License{Name: "PolyForm Small Business License 1.0.0", SourceAvailable: true,
+		URL: "https://spdx.org/licenses/PolyForm-Small-Business-1.0.0.html", Restriction: Forbidden}
+	PostgreSQL = License{Name: "PostgreSQL License", URL: "https://spdx.org/licenses/PostgreSQL.html",
+		Restriction: Unrestricted}
 	Python20 = License{Name: "Python License 2.0", URL: "https://spdx.org/licenses/Python-2.0.html",
 		Restriction: Unrestricted}
 	PSF = License{Name: "Python Software Foundation license", URL: "https://spdx.org/licenses/PSF-2.0.html",
 		Restriction: Unrestricted}
+	SSPL10 = License{Name: "Server Side Public License v1", SourceAvailable: true,
+		URL: "https://spdx.org/licenses/SSPL-1.0.html", Restriction: Forbidden}
 	PublicDomain = License{Name: "Public domain", Restriction: Unrestricted}
 	Unicode2015  = License{Name: "Unicode License Agreement for Data Files and Software (2015)",
 		URL: "https://spdx.org/licenses/Unicode-DFS-2015.html", Restriction: Unrestricted}
@@ -99,6 +147,8 @@ var (
 		URL: "https://spdx.org/licenses/Unlicense.html", Restriction: Unrestricted}
 	WTFPL = License{Name: "Do What The F*ck You Want To Public License",
 		URL: "https://spdx.org/licenses/WTFPL.html", Restriction: Unrestricted}
+	X11  = License{Name: "X11 License", URL: "https://spdx.org/licenses/X11.html", Restriction: Unrestricted}
+	Zlib = License{Name: "zlib License", URL: "https://spdx.org/licenses/Zlib.html", Restriction: Unrestricted}
 )
 
 // https://spdx.org/licenses/
@@ -111,20 +161,27 @@ var (
 	SpdxIdentifiers = map[string]License{
 		"0BSD":              ZeroBSD,
 		"Apache-2.0":        Apache2,
+		"Apache-1.1":        Apache11,
 		"AFL-2.1":           AFL21,
 		"AFLv2.1":           AFL21,
 		"AGPL-1.0-only":     AGPL1Only,
 		"AGPL-1.0-or-later": AGPL1OrLater,
 		"AGPL-3.0-only":     AGPL3Only,
 		"AGPL-3.0-or-later": AGPL3OrLater,
+		"Artistic-2.0":      Artistic20,
 		"BSD-1-Clause":      BSD1,
 		"BSD-2-Clause":      BSD2,
 		"BSD-3-Clause":      BSD3,
+		"BSD-4-Clause":      BSD4,
+		"BSL-1.0":           BSL10,
 		"CC-BY-3.0":         CcBy30,
 		"CC-BY-4.0":         CcBy40,
 		"CC-BY-SA-4.0":      CcBySa40,
 		"CC0-1.0":           Cc010,
+		"CDDL-1.0":          CDDL10,
+		"CDDL-1.1":          CDDL11,
 		"EPL-1.0":           EPL10,
+		"EPL-2.0":           EPL20,
 		"GPL-1.0-only":      GPL1Only,
 		"GPL-1.0-or-later":  GPL1OrLater,
 		"GPL-2.0-only":      GPL2Only,
@@ -139,18 +196,39 @@ var (
 		"LGPL-3.0-only":     LGPL3Only,
 		"LGPL-3.0-or-later": LGPL3OrLater,
 		"MIT":               MIT,
+		"MIT-0":             MIT0,
 		"MPL-1.1":           MPL11,
 		"MPL-2.0":           MPL2,
 		"ODC-By-1.0":        ODCBy10,
 		"OFL-1.1":           OFL11,
+		"PostgreSQL":        PostgreSQL,
 		"PSF-2.0":           PSF,
 		"Python-2.0":        Python20,
 		"Unicode-DFS-2015":  Unicode2015,
 		"Unlicense":         Unlicense,
 		"WTFPL":             WTFPL,
+		"X11":               X11,
+		"Zlib":              Zlib,
+
+		// source-available licenses
+		"BUSL-1.1":                      BUSL11,
+		"Elastic-2.0":                   Elastic20,
+		"PolyForm-Noncommercial-1.0.0":  PolyFormNoncommercial100,
+		"PolyForm-Small-Business-1.0.0": PolyFormSmallBusiness100,
+		"SSPL-1.0":                      SSPL10,
 	}
 )
 
+// licenseNames returns the sorted names of the licenses.
+func licenseNames(licenses map[License]struct{}) []string {
+	names := make([]string, 0, len(licenses))
+	for license := range licenses {
+		names = append(names, license.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func expectsNotice(licenses map[License]struct{}) bool {
 	for license := range licenses {
 		if license.NoticeFile {
@@ -160,36 +238,57 @@ func expectsNotice(licenses map[License]struct{}) bool {
 	return false
 }
 
+// IsLicenseFile returns whether a file with the given name is a license
+// file (as opposed to a source file, or another metadata file).
+func IsLicenseFile(filename string) bool {
+	name := filepath.Base(filename)
+	return strings.HasPrefix(name, "COPYLEFT") ||
+		strings.HasPrefix(name, "COPYING") ||
+		strings.HasPrefix(name, "COPYRIGHT") ||
+		strings.HasPrefix(name, "LEGAL") ||
+		strings.HasPrefix(name, "LICENSE")
+}
+
 func DetectLicenses(packageName string, packageVersion string, files map[string][]byte) (map[License]struct{}, error) {
+	detection, err := DetectLicensesWithEvidence(packageName, packageVersion, files)
+	if err != nil {
+		return nil, err
+	}
+	return detection.Licenses(), nil
+}
+
+// DetectLicensesWithEvidence is like DetectLicenses, but also reports
+// which files each license was detected in.
+func DetectLicensesWithEvidence(packageName string, packageVersion string, files map[string][]byte) (Detection, error) {
+	return CurrentDatabase().DetectLicensesWithEvidence(packageName, packageVersion, files)
+}
 
-	if knownDependencies, isKnown := knownDependencies(packageName, packageVersion); isKnown {
-		licenses := map[License]struct{}{}
+// DetectLicensesWithEvidence is like the function of the same name,
+// but uses the overrides, ignored files and curations of d rather than
+// of CurrentDatabase.
+func (d *Database) DetectLicensesWithEvidence(packageName string, packageVersion string, files map[string][]byte) (Detection, error) {
+
+	if knownDependencies, isKnown := d.knownDependencies(packageName, packageVersion, files); isKnown {
+		detection := make(Detection, len(knownDependencies))
 		for _, license := range knownDependencies {
-			licenses[license] = struct{}{}
+			detection[license] = []Evidence{{Source: EvidenceOverride}}
 		}
-		return licenses, nil
+		return detection, nil
 	}
 
+	ignoredFiles := d.IgnoredFiles
 	licenses := make(map[License][]string)
-	hasNotice := false
+	sources := make(map[string]EvidenceSource)
+	notices := []string(nil)
+	licenseFiles := make(map[string]struct{})
 	hasLicenseFile := false
-	hasNonSPDXSource := false
+	unlicensedSources := []string(nil)
 	patents := []string(nil)
 
 loop:
 	for filename, filebody := range files {
 
-		switch filename {
-		case "github.com/miekg/dns/COPYRIGHT":
-			// This file identifies copyright holders, but
-			// the license info is in the LICENSE file.
-			continue loop
-		case "sigs.k8s.io/kustomize/kyaml/LICENSE_TEMPLATE":
-			// This is a template file for generated code,
-			// not an actual license file.
-			continue loop
-		case "github.com/telepresenceio/telepresence/v2/LICENSES.md":
-			// Licenses for telepresence are in LICENSE and not in LICENSES.md
+		if _, ignored := ignoredFiles[filename]; ignored {
 			continue loop
 		}
 
@@ -199,14 +298,20 @@ loop:
 		case strings.HasPrefix(name, "AUTHORS") ||
 			strings.HasPrefix(name, "CONTRIBUTORS"):
 			// Ignore this file; it does not identify a license.
-		case strings.HasPrefix(name, "COPYLEFT") ||
-			strings.HasPrefix(name, "COPYING") ||
-			strings.HasPrefix(name, "COPYRIGHT") ||
-			strings.HasPrefix(name, "LEGAL") ||
-			strings.HasPrefix(name, "LICENSE"):
+		case IsLicenseFile(name):
 			ls := IdentifyLicenses(filebody)
+			sources[filename] = EvidenceLicenseFile
 			if len(ls) == 0 {
-				return nil, fmt.Errorf("could not identify license in file %q", filename)
+				ls, _ = d.IdentifyCuratedLicenses(filebody)
+				sources[filename] = EvidenceCuration
+			}
+			if len(ls) == 0 {
+				return nil, &scanningerrors.DetectionError{
+					Name:    packageName,
+					Version: packageVersion,
+					Files:   []string{filename},
+					Reason:  fmt.Sprintf("could not identify license in file %q", filename),
+				}
 			}
 			if name == "LICENSE.docs" && len(ls) == 1 {
 				if _, isCc := ls[CcBySa40]; isCc {
@@ -219,21 +324,34 @@ loop:
 			for l := range ls {
 				licenses[l] = append(licenses[l], filename)
 			}
+			licenseFiles[filename] = struct{}{}
 			hasLicenseFile = true
 		case strings.HasPrefix(name, "NOTICE"):
-			hasNotice = true
+			notices = append(notices, filename)
 		case strings.HasPrefix(name, "PATENTS"):
 			// ignore this file, for now
 			patents = append(patents, filename)
 		default:
 			// This is a source file; look for an SPDX
-			// identifier.
+			// identifier, or failing that a license
+			// header.
 			ls, err := IdentifySPDXLicenses(filebody)
 			if err != nil {
+				var spdxErr *scanningerrors.UnknownSPDXError
+				if errors.As(err, &spdxErr) {
+					spdxErr.Name = packageName
+					spdxErr.Version = packageVersion
+					spdxErr.File = filename
+				}
 				return nil, err
 			}
+			sources[filename] = EvidenceSPDXTag
 			if len(ls) == 0 {
-				hasNonSPDXSource = true
+				ls = IdentifyLicenseHeaders(filename, filebody)
+				sources[filename] = EvidenceLicenseHeader
+			}
+			if len(ls) == 0 {
+				unlicensedSources = append(unlicensedSources, filename)
 			}
 			for l := range ls {
 				licenses[l] = append(licenses[l], filename)
@@ -241,13 +359,49 @@ loop:
 		}
 	}
 
-	bareLicenses := make(map[License]struct{}, len(licenses))
-	for license := range licenses {
-		bareLicenses[license] = struct{}{}
+	// The LGPL-3.0 is written as a set of additional permissions on top
+	// of the GPL-3.0, so LGPL-3.0 packages ship a copy of the GPL-3.0
+	// (usually as COPYING next to COPYING.LESSER).  That copy doesn't
+	// mean that the package is GPL-3.0 licensed.
+	if lgplFiles, isLGPL3 := licenses[LGPL3Only]; isLGPL3 {
+		lgplDirs := make(map[string]struct{}, len(lgplFiles))
+		for _, lgplFile := range lgplFiles {
+			lgplDirs[filepath.Dir(lgplFile)] = struct{}{}
+		}
+		var gplFiles []string
+		for _, gplFile := range licenses[GPL3Only] {
+			_, isLicenseFile := licenseFiles[gplFile]
+			_, hasLGPL := lgplDirs[filepath.Dir(gplFile)]
+			if !(isLicenseFile && hasLGPL) {
+				gplFiles = append(gplFiles, gplFile)
+			}
+		}
+		if len(gplFiles) == 0 {
+			delete(licenses, GPL3Only)
+		} else {
+			licenses[GPL3Only] = gplFiles
+		}
+	}
+
+	detection := make(Detection, len(licenses))
+	for license, licenseFiles := range licenses {
+		evidence := make([]Evidence, 0, len(licenseFiles))
+		for _, filename := range licenseFiles {
+			evidence = append(evidence, NewEvidence(sources[filename], filename, files[filename]))
+		}
+		sortEvidence(evidence)
+		detection[license] = evidence
 	}
 
-	if !expectsNotice(bareLicenses) && hasNotice {
-		return nil, errors.New("the NOTICE file is really only for the Apache 2.0 and MPL 2.0 licenses; something hokey is going on")
+	if !expectsNotice(detection.Licenses()) && len(notices) > 0 {
+		sort.Strings(notices)
+		return nil, &scanningerrors.AnomalyError{
+			Name:     packageName,
+			Version:  packageVersion,
+			Files:    notices,
+			Licenses: licenseNames(detection.Licenses()),
+			Reason:   "the NOTICE file is really only for the Apache 2.0 and MPL 2.0 licenses; something hokey is going on",
+		}
 	}
 	for _, patentFile := range patents {
 		// TODO: Check if the MPL has a patent grant.  A quick skimming says "seems to explicitly say no", but
@@ -269,19 +423,31 @@ loop:
 				}
 			}
 			if !hasOther {
-				return nil, errors.New("the Apache license contains a patent-grant, but there's a separate PATENTS file; something hokey is going on")
+				return nil, &scanningerrors.AnomalyError{
+					Name:     packageName,
+					Version:  packageVersion,
+					Files:    []string{patentFile},
+					Licenses: licenseNames(detection.Licenses()),
+					Reason:   "the Apache license contains a patent-grant, but there's a separate PATENTS file; something hokey is going on",
+				}
 			}
 		}
 	}
 
-	if !hasLicenseFile && hasNonSPDXSource {
-		return nil, errors.New("could not identify a license for all sources (had no global LICENSE file)")
+	if !hasLicenseFile && len(unlicensedSources) > 0 {
+		sort.Strings(unlicensedSources)
+		return nil, &scanningerrors.DetectionError{
+			Name:    packageName,
+			Version: packageVersion,
+			Files:   unlicensedSources,
+			Reason:  "could not identify a license for all sources (had no global LICENSE file)",
+		}
 	}
 
 	if len(licenses) == 0 {
 		panic(errors.New("should not happen"))
 	}
-	return bareLicenses, nil
+	return detection, nil
 }
 
 // IdentifySPDX takes the contents of a source-file and looks for SPDX
@@ -301,7 +467,7 @@ func IdentifySPDXLicenses(body []byte) (map[License]struct{}, error) {
 		id = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(id), "*/"))
 		license, licenseOK := SpdxIdentifiers[id]
 		if !licenseOK {
-			return nil, fmt.Errorf("unknown SPDX identifier %q", id)
+			return nil, &scanningerrors.UnknownSPDXError{ID: id}
 		}
 		licenses[license] = struct{}{}
 	}
@@ -410,6 +576,64 @@ func IdentifyLicenses(body []byte) map[License]struct{} {
 		licenses[MPL2] = struct{}{}
 	case reMatch(reCcBySa40, body):
 		licenses[CcBySa40] = struct{}{}
+	case reMatch(reApache11, body):
+		licenses[Apache11] = struct{}{}
+	case reMatch(reArtistic20, body):
+		licenses[Artistic20] = struct{}{}
+	case reMatch(reBSD4, body):
+		licenses[BSD4] = struct{}{}
+	case reMatch(reBSL10, body):
+		licenses[BSL10] = struct{}{}
+	case reMatch(reCc010, body):
+		licenses[Cc010] = struct{}{}
+	case reMatch(reCDDL10, body):
+		licenses[CDDL10] = struct{}{}
+	case reMatch(reCDDL11, body):
+		licenses[CDDL11] = struct{}{}
+	case reMatch(reEPL10, body):
+		licenses[EPL10] = struct{}{}
+	case reMatch(reEPL20, body):
+		licenses[EPL20] = struct{}{}
+	case reMatch(reGPL2, body):
+		licenses[GPL2Only] = struct{}{}
+	case reMatch(reGPL3, body):
+		licenses[GPL3Only] = struct{}{}
+	case reMatch(reLGPL2, body):
+		licenses[LGPL2Only] = struct{}{}
+	case reMatch(reLGPL21, body):
+		licenses[LGPL21Only] = struct{}{}
+	case reMatch(reLGPL3, body):
+		licenses[LGPL3Only] = struct{}{}
+	case reMatch(reMIT0, body):
+		licenses[MIT0] = struct{}{}
+	case reMatch(rePostgreSQL, body):
+		licenses[PostgreSQL] = struct{}{}
+	case reMatch(reX11, body):
+		licenses[X11] = struct{}{}
+	case reMatch(reZlib, body):
+		licenses[Zlib] = struct{}{}
+
+	// source-available licenses
+	case reMatch(reBUSL11, body):
+		licenses[BUSL11] = struct{}{}
+	case reMatch(reCommonsClause, body):
+		licenses[CommonsClause] = struct{}{}
+	case reMatch(reElastic20, body):
+		licenses[Elastic20] = struct{}{}
+	case reMatch(rePolyFormNoncommercial100, body):
+		licenses[PolyFormNoncommercial100] = struct{}{}
+	case reMatch(rePolyFormSmallBusiness100, body):
+		licenses[PolyFormSmallBusiness100] = struct{}{}
+	case reMatch(reSSPL10, body):
+		licenses[SSPL10] = struct{}{}
+	case reMatch(regexp.MustCompile(reCommonsClause.String()+`(?:-+\s*)?`+reApacheLicense.String()), body) ||
+		reMatch(regexp.MustCompile(reApacheLicense.String()+`(?:-+\s*)?`+reCommonsClause.String()), body):
+		licenses[CommonsClause] = struct{}{}
+		licenses[Apache2] = struct{}{}
+	case reMatch(regexp.MustCompile(reCommonsClause.String()+`(?:-+\s*)?`+reMIT.String()), body) ||
+		reMatch(regexp.MustCompile(reMIT.String()+`(?:-+\s*)?`+reCommonsClause.String()), body):
+		licenses[CommonsClause] = struct{}{}
+		licenses[MIT] = struct{}{}
 
 	// special-purpose hacks
 	case reMatch(regexp.MustCompile(fmt.Sprintf(`%s\n-+\n+AVL Tree:\n+%s`, reBSD2, reISC)), body):
@@ -37,14 +37,40 @@ func fmtLicenses(set map[detectlicense.License]struct{}) string {
 func TestIdentifyLicenses(t *testing.T) {
 	// Mapping of `testdata/{NAME}/` names to detectlicense constants
 	allLicenses := map[string]detectlicense.License{
+		"Apache11":     detectlicense.Apache11,
 		"Apache2":      detectlicense.Apache2,
+		"Artistic20":   detectlicense.Artistic20,
 		"BSD1":         detectlicense.BSD1,
 		"BSD2":         detectlicense.BSD2,
 		"BSD3":         detectlicense.BSD3,
+		"BSD4":         detectlicense.BSD4,
+		"BSL10":        detectlicense.BSL10,
+		"CC0-1.0":      detectlicense.Cc010,
+		"CDDL10":       detectlicense.CDDL10,
+		"CDDL11":       detectlicense.CDDL11,
+		"EPL10":        detectlicense.EPL10,
+		"EPL20":        detectlicense.EPL20,
+		"GPL2":         detectlicense.GPL2Only,
+		"GPL3":         detectlicense.GPL3Only,
 		"ISC":          detectlicense.ISC,
+		"LGPL2":        detectlicense.LGPL2Only,
+		"LGPL21":       detectlicense.LGPL21Only,
+		"LGPL3":        detectlicense.LGPL3Only,
 		"MIT":          detectlicense.MIT,
+		"MIT0":         detectlicense.MIT0,
 		"MPL2":         detectlicense.MPL2,
+		"PostgreSQL":   detectlicense.PostgreSQL,
+		"X11":          detectlicense.X11,
+		"Zlib":         detectlicense.Zlib,
 		"CC-BY-SA-4.0": detectlicense.CcBySa40,
+
+		// source-available licenses
+		"BUSL11":                   detectlicense.BUSL11,
+		"CommonsClause":            detectlicense.CommonsClause,
+		"Elastic20":                detectlicense.Elastic20,
+		"PolyFormNoncommercial100": detectlicense.PolyFormNoncommercial100,
+		"PolyFormSmallBusiness100": detectlicense.PolyFormSmallBusiness100,
+		"SSPL10":                   detectlicense.SSPL10,
 	}
 	dirInfos, err := os.ReadDir("testdata")
 	if err != nil {
@@ -85,3 +111,57 @@ func TestIdentifyLicenses(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectLicensesLGPL3(t *testing.T) {
+	gpl3, err := os.ReadFile(filepath.Join("testdata", "GPL3", "31a3d460bb3c7d98845187c716a30db81c44b615.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lgpl3, err := os.ReadFile(filepath.Join("testdata", "LGPL3", "a8a12e6867d7ee39c21d9b11a984066099b6fb6b.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := map[string]struct {
+		files    map[string][]byte
+		expected map[detectlicense.License]struct{}
+	}{
+		"lgpl3-with-gpl3-companion": {
+			files: map[string][]byte{
+				"example.com/lib/COPYING":        gpl3,
+				"example.com/lib/COPYING.LESSER": lgpl3,
+			},
+			expected: map[detectlicense.License]struct{}{
+				detectlicense.LGPL3Only: {},
+			},
+		},
+		"gpl3-in-other-directory": {
+			files: map[string][]byte{
+				"example.com/lib/COPYING.LESSER":  lgpl3,
+				"example.com/lib/tools/COPYING":   gpl3,
+				"example.com/lib/tools/main.go":   []byte("package main\n"),
+				"example.com/lib/internal/lib.go": []byte("package internal\n"),
+			},
+			expected: map[detectlicense.License]struct{}{
+				detectlicense.LGPL3Only: {},
+				detectlicense.GPL3Only:  {},
+			},
+		},
+	}
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			licenses, err := detectlicense.DetectLicenses("example.com/lib", "v1.0.0", tc.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !licenseListEqual(licenses, tc.expected) {
+				t.Errorf("wrong result:\n"+
+					"expected: %s\n"+
+					"received: %s\n",
+					fmtLicenses(tc.expected),
+					fmtLicenses(licenses))
+			}
+		})
+	}
+}
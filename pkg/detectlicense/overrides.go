@@ -0,0 +1,218 @@
+package detectlicense
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// Overrides are hard-coded licenses for dependencies whose license
+// can't be detected, keyed by "name@versions".  The versions are either
+// an exact version, or a VersionRange such as ">=1.0.0 <2.0.0"; so that
+// an override doesn't have to be changed for every upgrade of the
+// dependency.
+type Overrides map[string]Override
+
+// An Override is the licenses of the versions of a dependency that an
+// Overrides key covers.
+type Override struct {
+	Licenses []License
+	// LicenseSHA256, if set, is the hex SHA-256 of the dependency's
+	// license file.  The override only applies while the license file
+	// is unchanged, so that an upstream relicense still fails.
+	LicenseSHA256 string
+	// Reason is why the override is needed.
+	Reason string
+}
+
+// UnmarshalYAML reads an Override with the SPDX identifiers of its
+// licenses.
+func (o *Override) UnmarshalYAML(node *yaml.Node) error {
+	var raw struct {
+		Licenses      []string `yaml:"licenses"`
+		LicenseSHA256 string   `yaml:"licenseSHA256"`
+		Reason        string   `yaml:"reason"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	if err := checkFields(node, "detectlicense.Override", "licenses", "licenseSHA256", "reason"); err != nil {
+		return err
+	}
+	licenses, err := parseSPDXIDs(node, raw.Licenses)
+	if err != nil {
+		return err
+	}
+	*o = Override{Licenses: licenses, LicenseSHA256: raw.LicenseSHA256, Reason: raw.Reason}
+	return nil
+}
+
+// Lookup returns the override for a version of a dependency.  An entry
+// for the exact version wins over ranges; if several ranges contain the
+// version, the first key alphabetically wins.
+func (o Overrides) Lookup(name, version string) (Override, bool) {
+	if override, ok := o[name+"@"+version]; ok {
+		return override, true
+	}
+	keys := make([]string, 0, len(o))
+	for key := range o {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		keyName, versions, err := ParseOverrideKey(key)
+		if err == nil && keyName == name && versions.Contains(version) {
+			return o[key], true
+		}
+	}
+	return Override{}, false
+}
+
+// Validate returns an error if any of the keys can't be parsed.
+func (o Overrides) Validate() error {
+	for key := range o {
+		if _, _, err := ParseOverrideKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Covers returns whether the override applies to a dependency with the
+// given license files: always if it has no LicenseSHA256, or else if
+// one of the files has that hash.
+func (o Override) Covers(licenseFiles ...[]byte) bool {
+	if o.LicenseSHA256 == "" {
+		return true
+	}
+	for _, body := range licenseFiles {
+		sum := sha256.Sum256(body)
+		if strings.EqualFold(hex.EncodeToString(sum[:]), o.LicenseSHA256) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseOverrideKey splits an Overrides key in to the name of the
+// dependency and the versions that it covers.  The name may contain
+// "@" (as npm scopes do); the versions never do.
+func ParseOverrideKey(key string) (string, VersionRange, error) {
+	at := strings.LastIndexByte(key, '@')
+	if at <= 0 {
+		return "", VersionRange{}, fmt.Errorf("override %q: must be name@versions", key)
+	}
+	versions, err := ParseVersionRange(key[at+1:])
+	if err != nil {
+		return "", VersionRange{}, fmt.Errorf("override %q: %w", key, err)
+	}
+	return key[:at], versions, nil
+}
+
+// A VersionRange is a set of versions of a dependency: either an exact
+// version, or a space-separated list of comparisons (">=", ">", "<=",
+// "<" or "=" a version) that a version must all satisfy.  Comparisons
+// are between semantic versions, with an optional leading "v"; so a Go
+// pseudo-version is between the releases that it was made between, and
+// a pre-release is before its release.
+type VersionRange struct {
+	exact       string
+	comparisons []versionComparison
+}
+
+type versionComparison struct {
+	op      string
+	version string
+}
+
+// versionOps are the comparison operators, longest first so that ">="
+// isn't taken for ">".
+//
+//nolint:gochecknoglobals // Would be 'const'.
+var versionOps = []string{">=", "<=", ">", "<", "="}
+
+// ParseVersionRange parses a VersionRange.
+func ParseVersionRange(s string) (VersionRange, error) {
+	if s == "" {
+		return VersionRange{}, fmt.Errorf("empty version")
+	}
+	if !strings.ContainsAny(s, "<>=") {
+		return VersionRange{exact: s}, nil
+	}
+	var r VersionRange
+	for _, field := range strings.Fields(s) {
+		var op string
+		for _, versionOp := range versionOps {
+			if strings.HasPrefix(field, versionOp) {
+				op = versionOp
+				break
+			}
+		}
+		if op == "" {
+			return VersionRange{}, fmt.Errorf("version range %q: %q doesn't start with one of %s", s, field, strings.Join(versionOps, ", "))
+		}
+		version := canonicalVersion(strings.TrimPrefix(field, op))
+		if !semver.IsValid(version) {
+			return VersionRange{}, fmt.Errorf("version range %q: %q is not a semantic version", s, strings.TrimPrefix(field, op))
+		}
+		r.comparisons = append(r.comparisons, versionComparison{op: op, version: version})
+	}
+	return r, nil
+}
+
+// Contains returns whether a version is in the range.  A version that
+// isn't a semantic version is only in a range of that exact version.
+func (r VersionRange) Contains(version string) bool {
+	if r.comparisons == nil {
+		return version == r.exact
+	}
+	version = canonicalVersion(version)
+	if !semver.IsValid(version) {
+		return false
+	}
+	for _, c := range r.comparisons {
+		cmp := semver.Compare(version, c.version)
+		var ok bool
+		switch c.op {
+		case ">=":
+			ok = cmp >= 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case "<":
+			ok = cmp < 0
+		case "=":
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (r VersionRange) String() string {
+	if r.comparisons == nil {
+		return r.exact
+	}
+	fields := make([]string, 0, len(r.comparisons))
+	for _, c := range r.comparisons {
+		fields = append(fields, c.op+c.version)
+	}
+	return strings.Join(fields, " ")
+}
+
+// canonicalVersion adds the leading "v" that golang.org/x/mod/semver
+// needs and npm versions don't have.
+func canonicalVersion(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
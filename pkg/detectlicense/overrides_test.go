@@ -0,0 +1,95 @@
+package detectlicense
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultDatabase(t *testing.T) {
+	require.NoError(t, DefaultDatabase().Go.Validate())
+}
+
+func TestVersionRange(t *testing.T) {
+	testcases := map[string]struct {
+		Versions string
+		In       []string
+		NotIn    []string
+	}{
+		"exact": {
+			Versions: "0.2.2",
+			In:       []string{"0.2.2"},
+			NotIn:    []string{"0.2.3", "v0.2.2"},
+		},
+		"npm": {
+			Versions: ">=1.0.0 <2.0.0",
+			In:       []string{"1.0.0", "1.3.1", "v1.3.1", "2.0.0-rc.1"},
+			NotIn:    []string{"0.10.0", "2.0.0", "latest"},
+		},
+		"go-pseudo-version": {
+			Versions: "<v1.0.0",
+			In:       []string{"v0.0.0-20150301180006-535138d7bcd7", "v0.9.0", "v1.0.0-rc1"},
+			NotIn:    []string{"v1.0.0", "v1.0.1-0.20211109044230-42b52b674af5"},
+		},
+		"pseudo-version-bounds": {
+			Versions: ">v0.0.0-20150301180006-535138d7bcd7 <=v0.0.0-20160101000000-000000000000",
+			In:       []string{"v0.0.0-20150601000000-abcdefabcdef", "v0.0.0-20160101000000-000000000000"},
+			NotIn:    []string{"v0.0.0-20150301180006-535138d7bcd7", "v0.0.0-20170101000000-000000000000"},
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			versions, err := ParseVersionRange(tc.Versions)
+			require.NoError(t, err)
+			for _, version := range tc.In {
+				require.True(t, versions.Contains(version), version)
+			}
+			for _, version := range tc.NotIn {
+				require.False(t, versions.Contains(version), version)
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "~1.0.0 <2.0.0", ">=one"} {
+		_, err := ParseVersionRange(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestOverridesLookup(t *testing.T) {
+	overrides := Overrides{
+		"@scope/pkg@>=1.0.0 <2.0.0": {Licenses: []License{MIT}},
+		"@scope/pkg@1.5.0":          {Licenses: []License{Apache2}},
+		"other@>=1.0.0":             {Licenses: []License{BSD3}},
+	}
+	require.NoError(t, overrides.Validate())
+
+	override, ok := overrides.Lookup("@scope/pkg", "1.2.0")
+	require.True(t, ok)
+	require.Equal(t, []License{MIT}, override.Licenses)
+
+	override, ok = overrides.Lookup("@scope/pkg", "1.5.0")
+	require.True(t, ok)
+	require.Equal(t, []License{Apache2}, override.Licenses)
+
+	_, ok = overrides.Lookup("@scope/pkg", "2.0.0")
+	require.False(t, ok)
+	_, ok = overrides.Lookup("@scope/other", "1.0.0")
+	require.False(t, ok)
+
+	require.Error(t, Overrides{"pkg": {}}.Validate())
+	require.Error(t, Overrides{"pkg@>1.0.0.0": {}}.Validate())
+}
+
+func TestOverrideCovers(t *testing.T) {
+	license := []byte("Copyright (c) 2015 Example\n\nDo what you want.\n")
+	sum := sha256.Sum256(license)
+
+	require.True(t, Override{}.Covers())
+	guarded := Override{LicenseSHA256: hex.EncodeToString(sum[:])}
+	require.True(t, guarded.Covers([]byte("README"), license))
+	require.False(t, guarded.Covers([]byte("Copyright (c) 2015 Example\n\nAll rights reserved.\n")))
+	require.False(t, guarded.Covers())
+}
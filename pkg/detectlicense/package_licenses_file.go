@@ -1,6 +1,7 @@
 package detectlicense
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 
@@ -34,3 +35,61 @@ func ReadPackageLicensesFromFile(name string) (map[string]map[License]struct{},
 	}
 	return plm, nil
 }
+
+// AddPackageLicensesToFile sets the licenses of a package in a file that
+// ReadPackageLicensesFromFile reads, creating the file if it doesn't exist,
+// and keeping the rest of the file (including comments) as it is.  comment,
+// if not empty, is written as a comment above the package's entry.
+func AddPackageLicensesToFile(name, pkg string, ids []string, comment string) error {
+	value := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, id := range ids {
+		if _, ok := SpdxIdentifiers[id]; !ok {
+			return fmt.Errorf("%q is not a valid SPDX License identifier. See https://spdx.org/licenses/ for a full litst", id)
+		}
+		value.Content = append(value.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: id})
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.Kind == 0 {
+		// empty file
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: not a map of package names to SPDX License identifiers", name)
+	}
+
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == pkg {
+			if comment != "" {
+				root.Content[i].HeadComment = comment
+			}
+			root.Content[i+1] = value
+			found = true
+		}
+	}
+	if !found {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: pkg, HeadComment: comment}
+		root.Content = append(root.Content, key, value)
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(name, out.Bytes(), 0o644)
+}
@@ -48,3 +48,51 @@ func TestReadPackageLicensesFromFile_invalidName(t *testing.T) {
 	_, err = ReadPackageLicensesFromFile(fn)
 	require.Error(t, err)
 }
+
+func TestAddPackageLicensesToFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "unparsable-packages.yaml")
+
+	// Creates the file
+	require.NoError(t, AddPackageLicensesToFile(fn, "github.com/alpha/one", []string{"Apache-2.0"}, ""))
+	require.NoError(t, os.WriteFile(fn, append([]byte("# Reviewed by the compliance team\n\n"), mustReadFile(t, fn)...), 0o644))
+
+	// Adds to the file, keeping what is there
+	require.NoError(t, AddPackageLicensesToFile(fn, "github.com/beta/two", []string{"MIT", "LGPL-3.0-only"},
+		"LICENSE is MIT with a typo fixed\nvendored code is LGPL"))
+	// Replaces an entry
+	require.NoError(t, AddPackageLicensesToFile(fn, "github.com/alpha/one", []string{"BSD-3-Clause"}, "not Apache after all"))
+
+	require.Equal(t, ""+
+		"# Reviewed by the compliance team\n"+
+		"\n"+
+		"# not Apache after all\n"+
+		"github.com/alpha/one:\n"+
+		"  - BSD-3-Clause\n"+
+		"# LICENSE is MIT with a typo fixed\n"+
+		"# vendored code is LGPL\n"+
+		"github.com/beta/two:\n"+
+		"  - MIT\n"+
+		"  - LGPL-3.0-only\n",
+		string(mustReadFile(t, fn)))
+
+	plm, err := ReadPackageLicensesFromFile(fn)
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[License]struct{}{
+		"github.com/alpha/one": {
+			BSD3: {},
+		},
+		"github.com/beta/two": {
+			MIT:       {},
+			LGPL3Only: {},
+		},
+	}, plm)
+
+	require.Error(t, AddPackageLicensesToFile(fn, "github.com/gamma/three", []string{"invalid-spdx-id"}, ""))
+}
+
+func mustReadFile(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(name)
+	require.NoError(t, err)
+	return body
+}
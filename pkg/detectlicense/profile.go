@@ -0,0 +1,129 @@
+package detectlicense
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
+)
+
+// DefaultProfile is the name of the Profile that is used if none is
+// given.
+const DefaultProfile = "ambassador"
+
+// profiles/ has the profiles that are shipped with go-mkopensource.
+//
+//go:embed profiles/*.yaml
+var shippedProfiles embed.FS
+
+// Profile is what the license checker knows about the organization
+// that runs it: which software is its own, and where its license
+// policy is documented.
+type Profile struct {
+	// Organization is the name of the organization, such as
+	// "Ambassador Labs".
+	Organization string `yaml:"organization"`
+	// ProprietarySoftware are the patterns of the names of the
+	// organization's own packages; see ProprietarySoftware.
+	ProprietarySoftware []string `yaml:"proprietarySoftware"`
+	// InternalUseLabel describes the licenses that are InternalUseOnly,
+	// in reports.
+	InternalUseLabel string `yaml:"internalUseLabel"`
+	// ProprietaryLicenseName is the name of the license of the
+	// organization's own software; see ProprietaryLicense.
+	ProprietaryLicenseName string `yaml:"proprietaryLicense"`
+	// PolicyURL is where the organization's license policy is
+	// documented; the explanations of the errors refer to it.
+	PolicyURL string `yaml:"policyURL"`
+}
+
+// ShippedProfiles returns the names of the profiles that are shipped
+// with go-mkopensource.
+func ShippedProfiles() []string {
+	entries, err := shippedProfiles.ReadDir("profiles")
+	if err != nil {
+		panic(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	return names
+}
+
+// LoadProfile returns the shipped profile called name, or else reads
+// the profile from the YAML file called name.  Anything that the file
+// leaves out is the same as in the "generic" profile.  An empty name
+// is DefaultProfile.
+func LoadProfile(name string) (*Profile, error) {
+	if name == "" {
+		name = DefaultProfile
+	}
+	generic, err := shippedProfiles.ReadFile("profiles/generic.yaml")
+	if err != nil {
+		panic(err)
+	}
+	var profile Profile
+	if err := decodeProfile(generic, &profile); err != nil {
+		panic(err)
+	}
+
+	data, err := shippedProfiles.ReadFile("profiles/" + name + ".yaml")
+	if err != nil {
+		// not a shipped profile
+		if data, err = os.ReadFile(name); err != nil {
+			return nil, err
+		}
+	}
+	if err := decodeProfile(data, &profile); err != nil {
+		return nil, fmt.Errorf("profile %s: %w", name, err)
+	}
+	for _, pattern := range profile.ProprietarySoftware {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("profile %s: %q: %w", name, pattern, err)
+		}
+	}
+	return &profile, nil
+}
+
+func decodeProfile(data []byte, profile *Profile) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	return decoder.Decode(profile)
+}
+
+// Policy returns the profile's organization and license policy, for
+// the explanations of the scanning errors.
+func (p *Profile) Policy() scanningerrors.Policy {
+	return scanningerrors.Policy{
+		Organization: p.Organization,
+		URL:          p.PolicyURL,
+	}
+}
+
+// Apply makes scanningerrors.ExplainErrors and NewReport refer to the
+// profile's organization and license policy.
+func (p *Profile) Apply() {
+	scanningerrors.SetPolicy(p.Policy())
+}
+
+// ProprietaryLicense returns the license that the organization's own
+// software has.
+func (p *Profile) ProprietaryLicense() License {
+	if p.ProprietaryLicenseName == "" {
+		return FirstPartyProprietary
+	}
+	return License{Name: p.ProprietaryLicenseName, Proprietary: true}
+}
+
+// Proprietary returns the organization's ProprietarySoftware, plus
+// proprietarySoftware.
+func (p *Profile) Proprietary(proprietarySoftware ...string) ProprietarySoftware {
+	return NewProprietarySoftware(append(append([]string(nil), p.ProprietarySoftware...), proprietarySoftware...)...)
+}
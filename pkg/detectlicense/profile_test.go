@@ -0,0 +1,65 @@
+package detectlicense_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+)
+
+func TestLoadProfile(t *testing.T) {
+	assert.Equal(t, []string{"ambassador", "generic"}, detectlicense.ShippedProfiles())
+
+	// The default profile is Ambassador's
+	profile, err := detectlicense.LoadProfile("")
+	require.NoError(t, err)
+	assert.Equal(t, "Ambassador Labs", profile.Organization)
+	assert.True(t, profile.Proprietary().IsProprietarySoftware("github.com/datawire/saas_app/cmd/saas_app"))
+	assert.Equal(t, detectlicense.AmbassadorProprietary, profile.ProprietaryLicense())
+
+	profile, err = detectlicense.LoadProfile("generic")
+	require.NoError(t, err)
+	assert.Equal(t, &detectlicense.Profile{
+		ProprietarySoftware:    []string{},
+		InternalUseLabel:       "Internal use only",
+		ProprietaryLicenseName: "proprietary first-party software",
+	}, profile)
+	assert.Equal(t, detectlicense.FirstPartyProprietary, profile.ProprietaryLicense())
+	assert.False(t, profile.Proprietary().IsProprietarySoftware("github.com/datawire/saas_app/cmd/saas_app"))
+
+	// A file only needs to say what differs from the generic profile
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "example.yaml")
+	require.NoError(t, os.WriteFile(fn, []byte(""+
+		"organization: Example Corp\n"+
+		"proprietarySoftware:\n"+
+		"  - github.com/example/*\n"+
+		"  - \"@example/*\"\n"+
+		"proprietaryLicense: Example Corp proprietary license\n"+
+		"policyURL: https://example.com/license-policy\n"), 0o644))
+	profile, err = detectlicense.LoadProfile(fn)
+	require.NoError(t, err)
+	assert.Equal(t, &detectlicense.Profile{
+		Organization:           "Example Corp",
+		ProprietarySoftware:    []string{"github.com/example/*", "@example/*"},
+		InternalUseLabel:       "Internal use only",
+		ProprietaryLicenseName: "Example Corp proprietary license",
+		PolicyURL:              "https://example.com/license-policy",
+	}, profile)
+	assert.Equal(t, detectlicense.License{Name: "Example Corp proprietary license", Proprietary: true}, profile.ProprietaryLicense())
+	assert.True(t, profile.Proprietary().IsProprietarySoftware("@example/ui"))
+
+	// Errors
+	_, err = detectlicense.LoadProfile(filepath.Join(dir, "nonexistent.yaml"))
+	assert.Error(t, err)
+	require.NoError(t, os.WriteFile(fn, []byte("organisation: Example Corp\n"), 0o644))
+	_, err = detectlicense.LoadProfile(fn)
+	assert.Error(t, err, "misspelled field")
+	require.NoError(t, os.WriteFile(fn, []byte("proprietarySoftware: [\"github.com/example/[\"]\n"), 0o644))
+	_, err = detectlicense.LoadProfile(fn)
+	assert.Error(t, err, "bad pattern")
+}
@@ -1,40 +1,74 @@
 package detectlicense
 
 import (
+	"fmt"
 	"os"
+	"path"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
-//nolint:gochecknoglobals // Would be 'const'.
-var ambassadorPrivateRepos = map[string]struct{}{
-	"github.com/datawire/telepresence2-proprietary/": {},
-	"github.com/datawire/saas_app/":                  {},
-	"github.com/datawire/telepresence-pro/":          {},
-}
-
-type AmbassadorProprietarySoftware map[string]struct{}
+// ProprietarySoftware is a set of patterns of the names of the
+// organization's own proprietary packages.  A pattern matches a name
+// if it matches the name or any of its parents, '/'-separated; so a Go
+// module path matches all of the module's packages, and
+// "github.com/ourorg/*" or "@ourorg/*" (see path.Match for the syntax)
+// matches everything from a GitHub organization or an npm scope.  A
+// trailing "/" is ignored.
+type ProprietarySoftware map[string]struct{}
 
-func GetAmbassadorProprietarySoftware(proprietarySoftware ...string) AmbassadorProprietarySoftware {
-	ambProprietarySoftware := AmbassadorProprietarySoftware{}
+// Deprecated: Use ProprietarySoftware.
+type AmbassadorProprietarySoftware = ProprietarySoftware
 
-	for k, v := range ambassadorPrivateRepos {
-		ambProprietarySoftware[k] = v
+// NewProprietarySoftware returns a ProprietarySoftware with the given
+// patterns.
+func NewProprietarySoftware(patterns ...string) ProprietarySoftware {
+	proprietarySoftware := ProprietarySoftware{}
+	for _, pattern := range patterns {
+		proprietarySoftware[pattern] = struct{}{}
 	}
+	return proprietarySoftware
+}
 
-	for _, v := range proprietarySoftware {
-		ambProprietarySoftware[v] = struct{}{}
+// GetAmbassadorProprietarySoftware returns the proprietary software of
+// the "ambassador" profile, plus proprietarySoftware.
+//
+// Deprecated: Use NewProprietarySoftware with the ProprietarySoftware
+// of a Profile.
+func GetAmbassadorProprietarySoftware(proprietarySoftware ...string) ProprietarySoftware {
+	profile, err := LoadProfile("ambassador")
+	if err != nil {
+		panic(err)
 	}
+	return profile.Proprietary(proprietarySoftware...)
+}
 
-	return ambProprietarySoftware
+// IsProprietarySoftware returns whether a Go package, Go module or
+// npm package is matched by any of the patterns.
+func (a ProprietarySoftware) IsProprietarySoftware(packageName string) bool {
+	for pattern := range a {
+		if matchProprietaryPattern(strings.TrimSuffix(pattern, "/"), packageName) {
+			return true
+		}
+	}
+	return false
 }
 
-func (a AmbassadorProprietarySoftware) IsProprietarySoftware(packageName string) bool {
-	_, ok := a[packageName]
-	return ok
+func matchProprietaryPattern(pattern, name string) bool {
+	for {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		slash := strings.LastIndexByte(name, '/')
+		if slash < 0 {
+			return false
+		}
+		name = name[:slash]
+	}
 }
 
-func (a AmbassadorProprietarySoftware) ReadProprietarySoftwareFile(name string) error {
+func (a ProprietarySoftware) ReadProprietarySoftwareFile(name string) error {
 	data, err := os.ReadFile(name)
 	if err != nil {
 		return err
@@ -46,6 +80,9 @@ func (a AmbassadorProprietarySoftware) ReadProprietarySoftwareFile(name string)
 	}
 
 	for _, v := range proprietarySoftware {
+		if _, err := path.Match(v, ""); err != nil {
+			return fmt.Errorf("%s: %q: %w", name, v, err)
+		}
 		a[v] = struct{}{}
 	}
 	return nil
@@ -1,6 +1,8 @@
 package detectlicense
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -8,7 +10,7 @@ import (
 
 func TestGetAmbassadorProprietarySoftware(t *testing.T) {
 	proprietarySoftware := GetAmbassadorProprietarySoftware("github.com/datawire/secretprogram")
-	require.Len(t, proprietarySoftware, len(ambassadorPrivateRepos)+1)
+	require.Len(t, proprietarySoftware, len(ambassadorProprietarySoftware(t))+1)
 	require.Contains(t, proprietarySoftware, "github.com/datawire/secretprogram")
 }
 
@@ -18,7 +20,56 @@ func TestReadProprietarySoftwareFile(t *testing.T) {
 	err := proprietarySoftware.ReadProprietarySoftwareFile("./testdata/proprietary_software.yaml")
 
 	require.NoError(t, err)
-	require.Len(t, proprietarySoftware, len(ambassadorPrivateRepos)+2)
+	require.Len(t, proprietarySoftware, len(ambassadorProprietarySoftware(t))+2)
 	require.Contains(t, proprietarySoftware, "github.com/datawire/secretprogram")
 	require.Contains(t, proprietarySoftware, "github.com/datawire/othersecretprogram")
 }
+
+func TestIsProprietarySoftware(t *testing.T) {
+	proprietarySoftware := GetAmbassadorProprietarySoftware(
+		"github.com/datawire/secretprogram",
+		"github.com/ourorg/*",
+		"@ourorg/*",
+		"gitlab.com/*/internal-?",
+	)
+
+	testcases := map[string]bool{
+		// exact
+		"github.com/datawire/secretprogram": true,
+		// the packages of a module
+		"github.com/datawire/secretprogram/pkg/secret": true,
+		"github.com/datawire/secretprogram2":           false,
+		// the built-in entries end in "/"
+		"github.com/datawire/saas_app":              true,
+		"github.com/datawire/saas_app/cmd/saas_app": true,
+		"github.com/datawire/saas_application":      false,
+		// globs
+		"github.com/ourorg/anything":         true,
+		"github.com/ourorg/anything/pkg/foo": true,
+		"github.com/ourorg":                  false,
+		"github.com/otherorg/anything":       false,
+		"@ourorg/ui-kit":                     true,
+		"@otherorg/ui-kit":                   false,
+		"ourorg-ui-kit":                      false,
+		"gitlab.com/team/internal-a/pkg":     true,
+		"gitlab.com/team/internal-ab":        false,
+	}
+	for name, expected := range testcases {
+		require.Equal(t, expected, proprietarySoftware.IsProprietarySoftware(name), name)
+	}
+}
+
+func TestReadProprietarySoftwareFile_invalidPattern(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "proprietary_software.yaml")
+	require.NoError(t, os.WriteFile(fn, []byte("- github.com/ourorg/[\n"), 0o644))
+
+	err := GetAmbassadorProprietarySoftware().ReadProprietarySoftwareFile(fn)
+	require.Error(t, err)
+}
+
+func ambassadorProprietarySoftware(t *testing.T) []string {
+	t.Helper()
+	profile, err := LoadProfile("ambassador")
+	require.NoError(t, err)
+	return profile.ProprietarySoftware
+}
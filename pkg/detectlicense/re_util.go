@@ -2,6 +2,7 @@ package detectlicense
 
 import (
 	"regexp"
+	"strings"
 )
 
 func reWrap(str string) string {
@@ -16,6 +17,24 @@ func reQuote(str string) string {
 	return regexp.QuoteMeta(str)
 }
 
+// reSmartQuotes makes the straight quotes in a regex match any quote
+// character; licenses that were copied from a web page tend to have
+// typographic quotes, and not always the same ones as the original.
+func reSmartQuotes(re string) string {
+	return strings.NewReplacer(`"`, `["'“”‘’]`, `'`, `["'“”‘’]`).Replace(re)
+}
+
+// reMarkdown takes a quoted license text (as returned by reQuote) and
+// makes the Markdown markup in it optional, so that the same regex
+// matches both the LICENSE.md and the plain-text rendering of a
+// license.
+func reMarkdown(re string) string {
+	re = regexp.MustCompile(`(?m)^#+ `).ReplaceAllLiteralString(re, `(?:#+ )?`)
+	re = regexp.MustCompile(`(?m)^> `).ReplaceAllLiteralString(re, `(?:> )?`)
+	re = regexp.MustCompile(`\\\]\\\((#[-a-z]+)\\\)`).ReplaceAllString(re, `\]?(?:\($1\))?`)
+	return strings.NewReplacer(`\[`, `\[?`, `\*`, `\**`, "`", "`?").Replace(re)
+}
+
 func reMatch(re *regexp.Regexp, str []byte) bool {
 	return regexp.MustCompile(`\A` + re.String() + `\z`).Match(str)
 }
@@ -12,12 +12,18 @@ import (
 )
 
 func GoListPackages(flags []string, pkgnames []string) ([]Package, error) {
+	return GoListPackagesIn("", flags, pkgnames)
+}
+
+// GoListPackagesIn is GoListPackages, but runs `go list` in dir.
+func GoListPackagesIn(dir string, flags []string, pkgnames []string) ([]Package, error) {
 	cmdline := []string{"go", "list"}
 	cmdline = append(cmdline, flags...)
 	cmdline = append(cmdline, "-json", "--")
 	cmdline = append(cmdline, pkgnames...)
 
 	cmd := exec.Command(cmdline[0], cmdline[1:]...)
+	cmd.Dir = dir
 	cmd.Stderr = os.Stderr
 
 	stdoutBytes, err := cmd.Output()
@@ -40,12 +46,19 @@ func GoListPackages(flags []string, pkgnames []string) ([]Package, error) {
 }
 
 func GoListModules(flags []string, modnames []string) ([]Module, error) {
+	return GoListModulesIn("", flags, modnames)
+}
+
+// GoListModulesIn is GoListModules, but runs `go list` in dir.  The
+// Dir of a vendored module is relative to dir.
+func GoListModulesIn(dir string, flags []string, modnames []string) ([]Module, error) {
 	cmdline := []string{"go", "list"}
 	cmdline = append(cmdline, flags...)
 	cmdline = append(cmdline, "-m", "-json", "--")
 	cmdline = append(cmdline, modnames...)
 
 	cmd := exec.Command(cmdline[0], cmdline[1:]...)
+	cmd.Dir = dir
 	cmd.Stderr = os.Stderr
 
 	stdoutBytes, err := cmd.Output()
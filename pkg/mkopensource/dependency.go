@@ -1,4 +1,4 @@
-package main
+package mkopensource
 
 import (
 	"fmt"
@@ -8,15 +8,18 @@ import (
 	"sort"
 )
 
+// GenerateDependencyList builds the list of dependencies to report.
+// If modEvidence is non-nil, the evidence for each license is included
+// in the list.  modCopyrights are the copyright notices of each module,
+// and modSums are the go.sum hashes of the modules that were verified.
 func GenerateDependencyList(modNames []string, modLicenses map[string]map[detectlicense.License]struct{},
-	modInfos map[string]*golist.Module, goVersion string,
+	modEvidence map[string]detectlicense.Detection, modCopyrights map[string][]string, modSums map[string]string, modInfos map[string]*golist.Module, goVersion string,
 	licenseRestriction detectlicense.LicenseRestriction) (dependencyList dependencies.DependencyInfo, errors []error) {
 	dependencyList = dependencies.NewDependencyInfo()
 	errors = []error{}
 
 	for _, modKey := range modNames {
-		ambassadorProprietary := isAmbassadorProprietary(modLicenses[modKey])
-		if ambassadorProprietary {
+		if isFirstPartyProprietary(modLicenses[modKey]) {
 			continue
 		}
 
@@ -27,9 +30,23 @@ func GenerateDependencyList(modNames []string, modLicenses map[string]map[detect
 			Version:  getDependencyVersion(modVal, goVersion),
 			Licenses: []string{},
 		}
+		if copyrights := modCopyrights[modKey]; len(copyrights) > 0 {
+			dependencyDetails.Copyrights = copyrights
+		}
+
+		if sum, ok := modSums[modKey]; ok {
+			dependencyDetails.Sum = sum
+		}
+
+		if modEvidence != nil {
+			dependencyDetails.Evidence = map[string][]detectlicense.Evidence{}
+		}
 
 		for license := range modLicenses[modKey] {
 			dependencyDetails.Licenses = append(dependencyDetails.Licenses, license.Name)
+			if modEvidence != nil {
+				dependencyDetails.Evidence[license.Name] = modEvidence[modKey][license]
+			}
 
 			if err := dependencies.CheckLicenseRestrictions(dependencyDetails, license.Name, licenseRestriction); err != nil {
 				errors = append(errors, err)
@@ -1,9 +1,9 @@
-package main_test
+package mkopensource_test
 
 import (
-	main "github.com/datawire/go-mkopensource/cmd/go-mkopensource"
 	. "github.com/datawire/go-mkopensource/pkg/detectlicense"
 	"github.com/datawire/go-mkopensource/pkg/golist"
+	"github.com/datawire/go-mkopensource/pkg/mkopensource"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -22,19 +22,19 @@ var (
 func TestGenerateDependencyListWhenLicenseIsAllowed(t *testing.T) {
 	licenses := map[string]map[License]struct{}{modNames[0]: {BSD1: {}}}
 
-	_, errors := main.GenerateDependencyList(modNames, licenses, modInfos, goVersion, Unrestricted)
+	_, errors := mkopensource.GenerateDependencyList(modNames, licenses, nil, nil, nil, modInfos, goVersion, Unrestricted)
 	require.Empty(t, errors)
 
-	_, errors = main.GenerateDependencyList(modNames, licenses, modInfos, goVersion, AmbassadorServers)
+	_, errors = mkopensource.GenerateDependencyList(modNames, licenses, nil, nil, nil, modInfos, goVersion, AmbassadorServers)
 	require.Empty(t, errors)
 }
 
 func TestGenerateDependencyListWhenLicenseIsForbidden(t *testing.T) {
 	licenses := map[string]map[License]struct{}{modNames[0]: {AGPL1Only: {}}}
 
-	_, errors := main.GenerateDependencyList(modNames, licenses, modInfos, goVersion, Unrestricted)
+	_, errors := mkopensource.GenerateDependencyList(modNames, licenses, nil, nil, nil, modInfos, goVersion, Unrestricted)
 	require.NotEmptyf(t, errors, "Expected at least one error but got none")
 
-	_, errors = main.GenerateDependencyList(modNames, licenses, modInfos, goVersion, AmbassadorServers)
+	_, errors = mkopensource.GenerateDependencyList(modNames, licenses, nil, nil, nil, modInfos, goVersion, AmbassadorServers)
 	require.NotEmptyf(t, errors, "Expected at least one error but got none")
 }
@@ -0,0 +1,131 @@
+package mkopensource
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// env is where a scan happens: the Options.Dir, FS, GoTool and VCS,
+// with the defaults filled in.
+type env struct {
+	dir    string
+	fsys   fs.FS
+	goTool GoTool
+	vcs    VCS
+}
+
+func newEnv(opts Options) (*env, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	e := &env{dir: dir, fsys: opts.FS, goTool: opts.GoTool, vcs: opts.VCS}
+	if e.fsys == nil {
+		e.fsys = os.DirFS("/")
+	}
+	if e.goTool == nil {
+		e.goTool = goCommand{}
+	}
+	if e.vcs == nil {
+		e.vcs = Git{}
+	}
+	return e, nil
+}
+
+// fsName returns the name in the FS of the file called filename, which
+// is either absolute, or relative to the directory of the scan.
+func (e *env) fsName(filename string) string {
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(e.dir, filename)
+	}
+	name := strings.TrimPrefix(filepath.ToSlash(filename), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func (e *env) readFile(filename string) ([]byte, error) {
+	return fs.ReadFile(e.fsys, e.fsName(filename))
+}
+
+func (e *env) readDir(dirname string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(e.fsys, e.fsName(dirname))
+}
+
+func (e *env) open(filename string) (fs.File, error) {
+	return e.fsys.Open(e.fsName(filename))
+}
+
+// openZip reads a zip file, such as a module zip in the module cache.
+func (e *env) openZip(filename string) (*zip.Reader, error) {
+	body, err := e.readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(body), int64(len(body)))
+}
+
+// hashZip is dirhash.HashZip, for a zip file that has already been
+// read.
+func hashZip(zipFile *zip.Reader) (string, error) {
+	var names []string
+	files := make(map[string]*zip.File, len(zipFile.File))
+	for _, file := range zipFile.File {
+		names = append(names, file.Name)
+		files[file.Name] = file
+	}
+	return dirhash.Hash1(names, func(name string) (io.ReadCloser, error) {
+		file, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("file %q not found in zip", name) // should never happen
+		}
+		return file.Open()
+	})
+}
+
+// hashDir is dirhash.HashDir, for a directory in the FS.
+func (e *env) hashDir(dir, prefix string) (string, error) {
+	root := e.fsName(dir)
+	var files []string
+	err := fs.WalkDir(e.fsys, root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		} else if name == root {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		files = append(files, path.Join(prefix, relName(root, name)))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return dirhash.Hash1(files, func(name string) (io.ReadCloser, error) {
+		return e.fsys.Open(path.Join(root, relName(prefix, name)))
+	})
+}
+
+// relName returns the name of a file in the FS relative to the
+// directory root, which it is in.
+func relName(root, name string) string {
+	if root == "." {
+		return name
+	}
+	return strings.TrimPrefix(name, root+"/")
+}
@@ -0,0 +1,366 @@
+package mkopensource_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/sumdb/dirhash"
+
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+	"github.com/datawire/go-mkopensource/pkg/golist"
+	"github.com/datawire/go-mkopensource/pkg/mkopensource"
+)
+
+// fakeGoTool is a GoTool for a program that has already been listed,
+// with a toolchain whose `go env` is env.  If mods is set, the program
+// has already been vendored too, and those are its modules.
+type fakeGoTool struct {
+	pkgs []golist.Package
+	mods []golist.Module
+	env  map[string]string
+	dirs []string
+}
+
+func (t *fakeGoTool) ModTidy(dir string) error {
+	t.dirs = append(t.dirs, dir)
+	return nil
+}
+
+func (t *fakeGoTool) ModVendor(string) error {
+	if t.mods == nil {
+		return errors.New("not vendored")
+	}
+	return nil
+}
+
+func (t *fakeGoTool) ModDownload(string, string) (mkopensource.ModuleDownload, error) {
+	return mkopensource.ModuleDownload{}, errors.New("no network")
+}
+
+func (t *fakeGoTool) ListPackages(dir string, _, _ []string) ([]golist.Package, error) {
+	t.dirs = append(t.dirs, dir)
+	return t.pkgs, nil
+}
+
+func (t *fakeGoTool) ListModules(_ string, _, patterns []string) ([]golist.Module, error) {
+	if t.mods == nil {
+		return nil, errors.New("not vendored")
+	}
+	var mods []golist.Module
+	for _, mod := range t.mods {
+		if (len(patterns) == 0 && mod.Main) || (len(patterns) == 1 && patterns[0] == mod.Path) {
+			mods = append(mods, mod)
+		}
+	}
+	return mods, nil
+}
+
+func (t *fakeGoTool) Env(_ string, vars ...string) ([]string, error) {
+	values := make([]string, len(vars))
+	for i, name := range vars {
+		values[i] = t.env[name]
+	}
+	return values, nil
+}
+
+type fakeVCS struct {
+	modified  bool
+	dir       string
+	filenames []string
+}
+
+func (v *fakeVCS) Modified(dir string, filenames ...string) (bool, error) {
+	v.dir, v.filenames = dir, filenames
+	return v.modified, nil
+}
+
+const hermeticModDir = "modcache/example.com/dep@v1.0.0"
+
+// hermeticModule returns the files and the GoTool of a program that
+// depends on a module whose LICENSE is license, and the go.sum hash of
+// that module.
+func hermeticModule(t *testing.T, license []byte) (fstest.MapFS, *fakeGoTool, string) {
+	t.Helper()
+	fsys := fstest.MapFS{
+		hermeticModDir + "/LICENSE": {Data: license},
+		hermeticModDir + "/dep.go":  {Data: []byte("package dep\n")},
+	}
+	var files []string
+	for name := range fsys {
+		files = append(files, "example.com/dep@v1.0.0/"+path.Base(name))
+	}
+	sum, err := dirhash.Hash1(files, func(name string) (io.ReadCloser, error) {
+		return fsys.Open(hermeticModDir + "/" + path.Base(name))
+	})
+	require.NoError(t, err)
+	fsys["work/go.sum"] = &fstest.MapFile{Data: []byte("example.com/dep v1.0.0 " + sum + "\n")}
+
+	app := &golist.Module{Path: "example.com/app", Main: true, Dir: "/work"}
+	dep := &golist.Module{Path: "example.com/dep", Version: "v1.0.0", Dir: "/" + hermeticModDir}
+	tool := &fakeGoTool{pkgs: []golist.Package{
+		{ImportPath: "fmt", Name: "fmt", Standard: true, DepOnly: true},
+		{ImportPath: "example.com/dep", Name: "dep", Dir: "/" + hermeticModDir, GoFiles: []string{"dep.go"}, Module: dep, DepOnly: true},
+		{ImportPath: "example.com/app", Name: "main", Dir: "/work", Module: app},
+	}}
+	return fsys, tool, sum
+}
+
+func TestScan_hermetic(t *testing.T) {
+	license, ok := detectlicense.CanonicalText(detectlicense.MIT)
+	require.True(t, ok)
+	const modDir = hermeticModDir
+	fsys, tool, sum := hermeticModule(t, license)
+	vcs := &fakeVCS{modified: true}
+
+	scanner, err := mkopensource.NewScanner(mkopensource.Options{
+		Package:       "example.com/app",
+		GoTarFilename: goTar(t),
+		Dir:           "/work",
+		FS:            fsys,
+		GoTool:        tool,
+		VCS:           vcs,
+	})
+	require.NoError(t, err)
+	result, err := scanner.Scan()
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+
+	assert.Equal(t, []string{"/work", "/work"}, tool.dirs)
+	assert.Equal(t, []string{"example.com/app"}, result.MainModules)
+	assert.Equal(t, []string{"example.com/app"}, result.MainCmdPackages)
+	require.Len(t, result.Dependencies.Dependencies, 2)
+	assert.Equal(t, "the Go language standard library (\"std\")", result.Dependencies.Dependencies[0].Name)
+	assert.Equal(t, "example.com/dep", result.Dependencies.Dependencies[1].Name)
+	assert.Equal(t, []string{detectlicense.MIT.Name}, result.Dependencies.Dependencies[1].Licenses)
+	assert.Equal(t, sum, result.Dependencies.Dependencies[1].Sum)
+
+	dirty, err := scanner.GoModDirty()
+	require.NoError(t, err)
+	assert.True(t, dirty)
+	assert.Equal(t, "/work", vcs.dir)
+	assert.Equal(t, []string{"go.mod", "go.sum"}, vcs.filenames)
+
+	// A module that has been changed since it was downloaded doesn't
+	// match go.sum.
+	fsys[modDir+"/dep.go"] = &fstest.MapFile{Data: []byte("package dep // changed\n")}
+	_, err = scanner.Scan()
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "checksum mismatch"), err.Error())
+	}
+
+	// And a module that isn't there can't be scanned.
+	delete(fsys, modDir+"/dep.go")
+	delete(fsys, modDir+"/LICENSE")
+	_, err = scanner.Scan()
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestScan_vendored(t *testing.T) {
+	license, ok := detectlicense.CanonicalText(detectlicense.MIT)
+	require.True(t, ok)
+	const zipName = "modcache/cache/download/example.com/dep/@v/v1.0.0.zip"
+	var zipBuf bytes.Buffer
+	zipWriter := zip.NewWriter(&zipBuf)
+	for name, body := range map[string][]byte{"LICENSE": license, "dep.go": []byte("package dep\n")} {
+		w, err := zipWriter.Create("example.com/dep@v1.0.0/" + name)
+		require.NoError(t, err)
+		_, err = w.Write(body)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zipWriter.Close())
+
+	testcases := map[string]struct {
+		env     map[string]string
+		inCache bool
+		edited  bool
+		err     string
+		warning string
+	}{
+		"in the module cache": {
+			env:     map[string]string{"GOMODCACHE": "/modcache", "GOPROXY": "off"},
+			inCache: true,
+		},
+		"edited": {
+			env:     map[string]string{"GOMODCACHE": "/modcache", "GOPROXY": "off"},
+			inCache: true,
+			edited:  true,
+			err:     "files do not match the module as recorded in go.sum",
+		},
+		"GOPROXY=off": {
+			env:     map[string]string{"GOMODCACHE": "/modcache", "GOPROXY": "off"},
+			warning: "isn't in the module cache, and GOPROXY=off rules out downloading it",
+		},
+		"GOFLAGS=-mod=vendor": {
+			env:     map[string]string{"GOMODCACHE": "/modcache", "GOFLAGS": "-trimpath -mod=vendor"},
+			warning: "isn't in the module cache, and GOFLAGS=-trimpath -mod=vendor rules out downloading it",
+		},
+		"no network": {
+			env: map[string]string{"GOMODCACHE": "/modcache"},
+			err: "no network",
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			fsys, tool, sum := hermeticModule(t, license)
+			depGo := "package dep\n"
+			if tc.edited {
+				depGo = "package dep // changed\n"
+			}
+			fsys["work/vendor/modules.txt"] = &fstest.MapFile{Data: []byte("# example.com/dep v1.0.0\nexample.com/dep\n")}
+			fsys["work/vendor/example.com/dep/LICENSE"] = &fstest.MapFile{Data: license}
+			fsys["work/vendor/example.com/dep/dep.go"] = &fstest.MapFile{Data: []byte(depGo)}
+			if tc.inCache {
+				fsys[zipName] = &fstest.MapFile{Data: zipBuf.Bytes()}
+			}
+			tool.mods = []golist.Module{
+				{Path: "example.com/app", Main: true, Dir: "/work"},
+				{Path: "example.com/dep", Version: "v1.0.0"},
+			}
+			tool.env = tc.env
+
+			scanner, err := mkopensource.NewScanner(mkopensource.Options{
+				Package:       "mod",
+				GoTarFilename: goTar(t),
+				Dir:           "/work",
+				FS:            fsys,
+				GoTool:        tool,
+				VCS:           &fakeVCS{},
+			})
+			require.NoError(t, err)
+			result, err := scanner.Scan()
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.err)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Empty(t, result.Errors)
+			require.Len(t, result.Dependencies.Dependencies, 2)
+			assert.Equal(t, []string{detectlicense.MIT.Name}, result.Dependencies.Dependencies[1].Licenses)
+			if tc.warning == "" {
+				assert.Empty(t, result.Warnings)
+				assert.Equal(t, sum, result.Dependencies.Dependencies[1].Sum)
+			} else if assert.Len(t, result.Warnings, 1) {
+				assert.Contains(t, result.Warnings[0].Error(), tc.warning)
+			}
+		})
+	}
+}
+
+func TestScan_toolchain(t *testing.T) {
+	license, ok := detectlicense.CanonicalText(detectlicense.BSD3)
+	require.True(t, ok)
+	fsys := fstest.MapFS{"goroot/LICENSE": {Data: license}}
+
+	// An explicit GoLicenseFile is read from the disk, not from FS.
+	otherLicense, ok := detectlicense.CanonicalText(detectlicense.MIT)
+	require.True(t, ok)
+	otherLicenseFile := filepath.Join(t.TempDir(), "LICENSE")
+	require.NoError(t, os.WriteFile(otherLicenseFile, otherLicense, 0o644))
+	app := &golist.Module{Path: "example.com/app", Main: true, Dir: "/work"}
+	tool := &fakeGoTool{
+		pkgs: []golist.Package{
+			{ImportPath: "fmt", Name: "fmt", Standard: true, DepOnly: true},
+			{ImportPath: "example.com/app", Name: "main", Dir: "/work", Module: app},
+		},
+		env: map[string]string{"GOROOT": "/goroot", "GOVERSION": "go1.23.6 X:loopvar"},
+	}
+
+	testCases := map[string]struct {
+		toolchain string
+		opts      mkopensource.Options
+		version   string
+		license   detectlicense.License
+	}{
+		"local toolchain":           {version: "v1.23.6", license: detectlicense.BSD3},
+		"newer toolchain in go.mod": {toolchain: "go1.24.1", version: "v1.24.1", license: detectlicense.BSD3},
+		"older toolchain in go.mod": {toolchain: "go1.23.2", version: "v1.23.6", license: detectlicense.BSD3},
+		"explicit version":          {toolchain: "go1.24.1", opts: mkopensource.Options{GoVersion: "1.22.4"}, version: "v1.22.4", license: detectlicense.BSD3},
+		"explicit prerelease":       {opts: mkopensource.Options{GoVersion: "go1.21rc2"}, version: "v1.21.0-rc.2", license: detectlicense.BSD3},
+		"explicit license":          {opts: mkopensource.Options{GoLicenseFile: otherLicenseFile}, version: "v1.23.6", license: detectlicense.MIT},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			goMod := "module example.com/app\n\ngo 1.23.0\n"
+			if tc.toolchain != "" {
+				goMod += "\ntoolchain " + tc.toolchain + "\n"
+			}
+			fsys["work/go.mod"] = &fstest.MapFile{Data: []byte(goMod)}
+
+			opts := tc.opts
+			opts.Package, opts.Dir, opts.FS, opts.GoTool, opts.VCS = "example.com/app", "/work", fsys, tool, &fakeVCS{}
+			opts.VerifySources = mkopensource.VerifyOff
+			scanner, err := mkopensource.NewScanner(opts)
+			require.NoError(t, err)
+			result, err := scanner.Scan()
+			require.NoError(t, err)
+			require.Empty(t, result.Errors)
+
+			assert.Equal(t, tc.version, result.GoVersion)
+			require.Len(t, result.Dependencies.Dependencies, 1)
+			assert.Equal(t, tc.version, result.Dependencies.Dependencies[0].Version)
+			assert.Equal(t, []string{tc.license.Name}, result.Dependencies.Dependencies[0].Licenses)
+		})
+	}
+}
+
+func TestScan_concurrentDatabases(t *testing.T) {
+	// A license file that only a curation can identify.
+	license := []byte("You may do anything with this software, except blame us.\n")
+	curated := func(curatedLicense detectlicense.License) *detectlicense.Database {
+		db := detectlicense.DefaultDatabase()
+		db.Merge(&detectlicense.Database{Curations: map[string]detectlicense.Curation{
+			detectlicense.CurationKey(license): {Licenses: []detectlicense.License{curatedLicense}},
+		}})
+		return db
+	}
+	profile, err := detectlicense.LoadProfile("generic")
+	require.NoError(t, err)
+	profile.Organization = "Example Corp"
+
+	testCases := map[string]struct {
+		opts    mkopensource.Options
+		license detectlicense.License
+		err     string
+	}{
+		"MIT":          {opts: mkopensource.Options{Database: curated(detectlicense.MIT)}, license: detectlicense.MIT},
+		"BSD":          {opts: mkopensource.Options{Database: curated(detectlicense.BSD3)}, license: detectlicense.BSD3},
+		"uncurated":    {opts: mkopensource.Options{Profile: profile}, err: "proprietary Example Corp software"},
+		"uncurated-db": {opts: mkopensource.Options{Database: detectlicense.DefaultDatabase()}, err: "proprietary Ambassador Labs software"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			fsys, tool, _ := hermeticModule(t, license)
+			opts := tc.opts
+			opts.Package, opts.GoTarFilename, opts.Dir, opts.FS, opts.GoTool, opts.VCS = "example.com/app", goTar(t), "/work", fsys, tool, &fakeVCS{}
+			scanner, err := mkopensource.NewScanner(opts)
+			require.NoError(t, err)
+
+			for i := 0; i < 5; i++ {
+				result, err := scanner.Scan()
+				require.NoError(t, err)
+				if tc.err != "" {
+					require.Len(t, result.Errors, 1)
+					assert.Contains(t, result.ExplainErrors().Error(), tc.err)
+					continue
+				}
+				require.Empty(t, result.Errors)
+				require.Len(t, result.Dependencies.Dependencies, 2)
+				assert.Equal(t, []string{tc.license.Name}, result.Dependencies.Dependencies[1].Licenses)
+			}
+		})
+	}
+}
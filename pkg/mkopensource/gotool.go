@@ -0,0 +1,144 @@
+package mkopensource
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/datawire/go-mkopensource/pkg/golist"
+)
+
+// A GoTool runs the go command for a Scanner; so that a program that
+// embeds the Scanner can scan without a Go toolchain, or with a
+// different one.  Each method runs the command in the directory dir,
+// which is the Scanner's Options.Dir.
+type GoTool interface {
+	// ModTidy runs `go mod tidy`.
+	ModTidy(dir string) error
+	// ModVendor runs `go mod vendor`, which writes the packages and
+	// vendor/modules.txt.
+	ModVendor(dir string) error
+	// ModDownload runs `go mod download -json` for a module, which is
+	// "path@version".
+	ModDownload(dir, module string) (ModuleDownload, error)
+	// ListPackages runs `go list -json` with the given flags, for the
+	// packages matched by patterns.
+	ListPackages(dir string, flags, patterns []string) ([]golist.Package, error)
+	// ListModules runs `go list -m -json` with the given flags, for
+	// the given modules (or the main module(s), if there are none).
+	ListModules(dir string, flags, modules []string) ([]golist.Module, error)
+	// Env runs `go env` for the given variables, and returns their
+	// values in the same order.
+	Env(dir string, vars ...string) ([]string, error)
+}
+
+// ModuleDownload is the output of `go mod download -json`.
+type ModuleDownload struct {
+	Path    string
+	Version string
+	Error   string
+	Zip     string
+	Sum     string
+	Origin  *struct {
+		VCS  string
+		URL  string
+		Ref  string
+		Hash string
+	}
+}
+
+// goCommand is the GoTool that runs the go command in $PATH.
+type goCommand struct{}
+
+func (goCommand) ModTidy(dir string) error {
+	tidyCmd := exec.Command("go", "mod", "tidy")
+	tidyCmd.Dir = dir
+	out, err := tidyCmd.CombinedOutput()
+	if err != nil {
+		log.Printf("'go mod tidy' failed:\n%s\n", out)
+		return fmt.Errorf("'go mod tidy' failed: %w", err)
+	}
+	return nil
+}
+
+func (tool goCommand) ModVendor(dir string) error {
+	cmd := exec.Command("go", "mod", "vendor")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		errInstall := findAndGetDependencies(dir, string(out))
+		if errInstall == nil {
+			return tool.ModVendor(dir)
+		}
+		return fmt.Errorf("%q: %w", []string{"go", "mod", "vendor"}, err)
+	}
+	return nil
+}
+
+func findAndGetDependencies(dir, outputFromModVendor string) error {
+	lines := strings.Split(outputFromModVendor, "\n")
+	var dependenciesToInstall []string
+	for _, line := range lines {
+		if strings.Contains(line, "go get") {
+			dependenciesToInstall = append(dependenciesToInstall, line)
+		}
+	}
+	if len(dependenciesToInstall) <= 0 {
+		log.Println(outputFromModVendor)
+		return fmt.Errorf("none dependency required installation")
+	}
+	for _, dependency := range dependenciesToInstall {
+		command := strings.Split(strings.TrimSpace(dependency), " ")
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Dir = dir
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			log.Printf("Error installing dependency %v", err)
+			return fmt.Errorf("%q: %w", []string{"go", "mod", "vendor"}, err)
+		}
+	}
+	return nil
+}
+
+func (goCommand) ModDownload(dir, module string) (ModuleDownload, error) {
+	cmd := exec.Command("go", "mod", "download", "-json", module)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return ModuleDownload{}, fmt.Errorf("%q: %w", cmd.Args, err)
+	}
+	var download ModuleDownload
+	if err := json.Unmarshal(out, &download); err != nil {
+		return ModuleDownload{}, fmt.Errorf("%q: %w", cmd.Args, err)
+	}
+	if download.Error != "" {
+		return ModuleDownload{}, fmt.Errorf("%q: %s", cmd.Args, download.Error)
+	}
+	return download, nil
+}
+
+func (goCommand) ListPackages(dir string, flags, patterns []string) ([]golist.Package, error) {
+	return golist.GoListPackagesIn(dir, flags, patterns)
+}
+
+func (goCommand) ListModules(dir string, flags, modules []string) ([]golist.Module, error) {
+	return golist.GoListModulesIn(dir, flags, modules)
+}
+
+func (goCommand) Env(dir string, vars ...string) ([]string, error) {
+	cmd := exec.Command("go", append([]string{"env"}, vars...)...)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", cmd.Args, err)
+	}
+	values := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	if len(values) != len(vars) {
+		return nil, fmt.Errorf("%q: expected %d values, got %d", cmd.Args, len(vars), len(values))
+	}
+	return values, nil
+}
@@ -0,0 +1,164 @@
+package mkopensource
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+
+	"github.com/datawire/go-mkopensource/pkg/dependencies"
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+)
+
+// html.tmpl and third_party_licenses.tmpl are self-contained pages (no
+// external stylesheets or scripts), so that they can be served from
+// anywhere, including from inside a product's own web UI; style.tmpl
+// is the stylesheet that they share.
+//
+//go:embed *.tmpl
+var htmlTemplateFiles embed.FS
+
+//nolint:gochecknoglobals // Would be 'const'.
+var (
+	htmlTemplates = template.Must(template.ParseFS(htmlTemplateFiles, "*.tmpl"))
+)
+
+type restrictionTier struct {
+	restriction detectlicense.LicenseRestriction
+	name        string
+}
+
+// restrictionTiers returns the restriction tiers, from least to most
+// restrictive, with how to describe them to a reader.
+func restrictionTiers(internalUseLabel string) []restrictionTier {
+	return []restrictionTier{
+		{detectlicense.Unrestricted, "Unrestricted"},
+		{detectlicense.InternalUseOnly, internalUseLabel},
+		{detectlicense.Forbidden, "Forbidden"},
+	}
+}
+
+type htmlReport struct {
+	Header       string
+	Tiers        []htmlTier
+	Licenses     []*htmlLicense
+	Dependencies []htmlDependency
+}
+
+type htmlTier struct {
+	Name  string
+	Count int
+}
+
+type htmlLicense struct {
+	Name        string
+	URL         string
+	Anchor      string
+	Restriction string
+	Count       int
+}
+
+type htmlDependency struct {
+	Name       string
+	Version    string
+	Anchor     string
+	Licenses   []htmlDependencyLicense
+	Copyrights []string
+}
+
+type htmlDependencyLicense struct {
+	*htmlLicense
+	Evidence []detectlicense.Evidence
+}
+
+// htmlAnchor turns a name in to something that is safe to use as the
+// id of an HTML element: letters, digits, "." and "-" are kept, and
+// every other byte (including "_") is escaped as "_XX", so that two
+// different names never get the same anchor.
+func htmlAnchor(prefix, name string) string {
+	var anchor strings.Builder
+	anchor.WriteString(prefix)
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '.', c == '-':
+			anchor.WriteByte(c)
+		default:
+			fmt.Fprintf(&anchor, "_%02X", c)
+		}
+	}
+	return anchor.String()
+}
+
+func restrictionName(tiers []restrictionTier, restriction detectlicense.LicenseRestriction) string {
+	for _, tier := range tiers {
+		if tier.restriction == restriction {
+			return tier.name
+		}
+	}
+	return "Unknown"
+}
+
+// htmlOutput writes a static HTML page describing the dependencies: a
+// summary of how many dependencies use each license and restriction
+// tier, a sortable table of the dependencies, and a section for each
+// dependency with its licenses, the evidence for them (if the
+// dependencyList has it), and its copyright notices.  A dependency is counted in
+// the tier of its most restrictive license.  internalUseLabel is how
+// to describe the InternalUseOnly tier.
+func htmlOutput(output *bytes.Buffer, header string, dependencyList dependencies.DependencyInfo, internalUseLabel string) error {
+	tiers := restrictionTiers(internalUseLabel)
+	report := htmlReport{
+		Header: header,
+	}
+
+	licenses := make(map[string]*htmlLicense)
+	tierCounts := make(map[detectlicense.LicenseRestriction]int)
+	for _, dependency := range dependencyList.Dependencies {
+		htmlDep := htmlDependency{
+			Name:       dependency.Name,
+			Version:    dependency.Version,
+			Anchor:     htmlAnchor("dependency-", dependency.Name),
+			Copyrights: dependency.Copyrights,
+		}
+		tier := detectlicense.Unrestricted
+		for _, licenseName := range dependency.Licenses {
+			license, err := dependencies.GetLicenseFromName(licenseName)
+			if err != nil {
+				return err
+			}
+			if license.Restriction < tier {
+				tier = license.Restriction
+			}
+			htmlLic, ok := licenses[licenseName]
+			if !ok {
+				htmlLic = &htmlLicense{
+					Name:        license.Name,
+					URL:         license.URL,
+					Anchor:      htmlAnchor("license-", license.Name),
+					Restriction: restrictionName(tiers, license.Restriction),
+				}
+				licenses[licenseName] = htmlLic
+				report.Licenses = append(report.Licenses, htmlLic)
+			}
+			htmlLic.Count++
+			htmlDep.Licenses = append(htmlDep.Licenses, htmlDependencyLicense{
+				htmlLicense: htmlLic,
+				Evidence:    dependency.Evidence[licenseName],
+			})
+		}
+		tierCounts[tier]++
+		report.Dependencies = append(report.Dependencies, htmlDep)
+	}
+
+	sort.Slice(report.Licenses, func(i, j int) bool {
+		return report.Licenses[i].Name < report.Licenses[j].Name
+	})
+	for _, tier := range tiers {
+		report.Tiers = append(report.Tiers, htmlTier{Name: tier.name, Count: tierCounts[tier.restriction]})
+	}
+
+	return htmlTemplates.ExecuteTemplate(output, "html.tmpl", report)
+}
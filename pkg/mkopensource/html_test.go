@@ -0,0 +1,40 @@
+package mkopensource_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/go-mkopensource/pkg/dependencies"
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+	"github.com/datawire/go-mkopensource/pkg/mkopensource"
+)
+
+func TestWriteHTML_anchors(t *testing.T) {
+	// Names that only differ in punctuation still get different
+	// anchors.
+	names := []string{"example.com/a/b", "example.com/a-b", "example.com/x.y", "example.com/x_y", "example.com/x y"}
+	result := &mkopensource.Result{Package: "mod", MainModules: []string{"example.com/app"}}
+	for _, name := range names {
+		result.Dependencies.Dependencies = append(result.Dependencies.Dependencies, dependencies.Dependency{
+			Name:     name,
+			Version:  "v1.0.0",
+			Licenses: []string{detectlicense.MIT.Name},
+		})
+	}
+	output := new(bytes.Buffer)
+	require.NoError(t, result.WriteHTML(output, "Internal use only"))
+
+	ids := make(map[string]struct{})
+	for _, match := range regexp.MustCompile(`<article id="([^"]*)">`).FindAllStringSubmatch(output.String(), -1) {
+		ids[match[1]] = struct{}{}
+		assert.True(t, strings.Contains(output.String(), `<a href="#`+match[1]+`">`), "no link to %q", match[1])
+	}
+	assert.Len(t, ids, len(names))
+	assert.Contains(t, ids, "dependency-example.com_2Fa_2Fb")
+	assert.Contains(t, ids, "dependency-example.com_2Fx_5Fy")
+}
@@ -0,0 +1,317 @@
+package mkopensource
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"go/version"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"github.com/datawire/go-mkopensource/pkg/golist"
+)
+
+func loadGoTar(goTarFilename string) (version string, license []byte, err error) {
+	goTarFile, err := os.Open(goTarFilename)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() { _ = goTarFile.Close() }()
+	goTarUncompressed, err := gzip.NewReader(goTarFile)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() { _ = goTarUncompressed.Close() }()
+	goTar := tar.NewReader(goTarUncompressed)
+	vrx := regexp.MustCompile(`go(\d+\.\d+\.\d+(?:-\S+)?)`)
+	for {
+		header, err := goTar.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", nil, err
+		}
+		switch header.Name {
+		case "go/VERSION":
+			fc, err := io.ReadAll(goTar)
+			if err != nil {
+				return "", nil, err
+			}
+			if m := vrx.FindStringSubmatch(string(fc)); len(m) == 2 {
+				version = "v" + m[1]
+			}
+		case "go/LICENSE":
+			fc, err := io.ReadAll(goTar)
+			if err != nil {
+				return "", nil, err
+			}
+			license = fc
+		}
+		if version != "" && license != nil {
+			break
+		}
+	}
+	if version == "" || license == nil {
+		return "", nil, fmt.Errorf("file %q did not contain %q or %q", goTarFilename, "go/VERSION", "go/LICENSE")
+	}
+	return version, license, nil
+}
+
+// loadStdlib returns the version and license of the Go stdlib: from
+// the Options.GoTarFilename tarball, if there is one; or else from
+// Options.GoVersion and Options.GoLicenseFile, with whichever of them is
+// empty filled in from the toolchain that the GoTool runs.
+func loadStdlib(env *env, opts Options) (version string, license []byte, err error) {
+	if opts.GoTarFilename != "" {
+		return loadGoTar(opts.GoTarFilename)
+	}
+
+	if opts.GoVersion != "" {
+		version, err = parseGoVersion(opts.GoVersion)
+	} else {
+		version, err = loadToolchainVersion(env)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if opts.GoLicenseFile != "" {
+		license, err = os.ReadFile(opts.GoLicenseFile)
+	} else {
+		license, err = loadToolchainLicense(env)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return version, license, nil
+}
+
+// loadToolchainVersion returns the version of the toolchain that the
+// GoTool runs, `go env GOVERSION`; unless the toolchain directive of
+// go.mod asks for a newer one, which is what the module is built with
+// wherever the go command is allowed to switch toolchains.
+func loadToolchainVersion(env *env) (string, error) {
+	vars, err := env.goTool.Env(env.dir, "GOVERSION")
+	if err != nil {
+		return "", err
+	}
+	// A toolchain built with GOEXPERIMENTs has them after the version:
+	// "go1.23.6 X:loopvar".
+	goVersion := ""
+	if fields := strings.Fields(vars[0]); len(fields) > 0 {
+		goVersion = fields[0]
+	}
+
+	body, err := env.readFile("go.mod")
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err == nil {
+		goMod, err := modfile.Parse(filepath.Join(env.dir, "go.mod"), body, nil)
+		if err != nil {
+			return "", err
+		}
+		if goMod.Toolchain != nil && version.Compare(goMod.Toolchain.Name, goVersion) > 0 {
+			goVersion = goMod.Toolchain.Name
+		}
+	}
+
+	return parseGoVersion(goVersion)
+}
+
+// loadToolchainLicense reads $GOROOT/LICENSE of the toolchain that the
+// GoTool runs.  The stdlib has had the same LICENSE for every release,
+// so it doesn't matter if that isn't the toolchain of the go.mod
+// toolchain directive.
+func loadToolchainLicense(env *env) ([]byte, error) {
+	vars, err := env.goTool.Env(env.dir, "GOROOT")
+	if err != nil {
+		return nil, err
+	}
+	if vars[0] == "" {
+		return nil, fmt.Errorf("'go env GOROOT' is empty")
+	}
+	return env.readFile(filepath.Join(vars[0], "LICENSE"))
+}
+
+// parseGoVersion returns the module-style version ("v1.23.6") of a Go
+// release version such as "go1.23.6", "1.23.6" or "v1.23.6".  A
+// prerelease such as "go1.21rc2" is "v1.21.0-rc.2".  A language version
+// such as "go1.23" names no particular release, and so is an error,
+// like it is for loadGoTar.
+func parseGoVersion(goVersion string) (string, error) {
+	if strings.HasPrefix(goVersion, "v") {
+		if !semver.IsValid(goVersion) || semver.Canonical(goVersion) != goVersion {
+			return "", fmt.Errorf("%q doesn't look like a Go release version", goVersion)
+		}
+		return goVersion, nil
+	}
+	goVer := "go" + strings.TrimPrefix(goVersion, "go")
+	m := regexp.MustCompile(`^go(\d+\.\d+)(?:\.(\d+)|(rc|beta)(\d+))?$`).FindStringSubmatch(goVer)
+	switch {
+	case m == nil || !version.IsValid(goVer):
+		return "", fmt.Errorf("%q doesn't look like a Go release version", goVersion)
+	case m[2] != "":
+		return "v" + m[1] + "." + m[2], nil
+	case m[3] != "":
+		return "v" + m[1] + ".0-" + m[3] + "." + m[4], nil
+	default:
+		return "", fmt.Errorf("%q is a Go language version, not a release version (such as %q)", goVersion, goVer+".0")
+	}
+}
+
+// scannedPackages is everything that is read from the disk before
+// detecting licenses.
+type scannedPackages struct {
+	goVersion string
+	listPkgs  []golist.Package
+	mainMods  map[string]struct{}
+	// pkgFiles are the files of each package (other than those of the
+	// main module(s)), and pkgNames are the names of those packages,
+	// sorted.
+	pkgFiles    map[string]map[string][]byte
+	pkgNames    []string
+	pkgVersions map[string]string
+	modSums     map[string]string
+	// verifyWarnings are the modules that didn't match go.sum, if
+	// that isn't fatal.
+	verifyWarnings []error
+}
+
+// loadPackages reads the packages in env matched by opts.Package (a
+// `go list` pattern, or "mod"), and the Go stdlib as described by
+// opts, reading up to opts.Jobs packages at once.  opts must have had
+// its defaults filled in by NewScanner.
+func loadPackages(env *env, opts Options) (*scannedPackages, error) {
+	// Let's do the expensive stuff (stuff that isn't entirely
+	// in-memory) up-front.
+
+	// `tar xf go{version}.src.tar.gz`, or `cat $(go env GOROOT)/LICENSE`
+	goVersion, goLicense, err := loadStdlib(env, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := env.goTool.ModTidy(env.dir); err != nil {
+		return nil, err
+	}
+
+	// `go list`
+	var mainMods map[string]struct{}
+	var listPkgs []golist.Package
+	if opts.Package == "mod" {
+		// `go list`
+		listPkgs, err = vendorList(env)
+		if err != nil {
+			return nil, err
+		}
+		listPkgs = append(listPkgs, golist.Package{}) // stdlib
+
+		// `go list -m`
+		mods, err := env.goTool.ListModules(env.dir, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		mainMods = make(map[string]struct{}, len(mods))
+		for _, mod := range mods {
+			mainMods[mod.Path] = struct{}{}
+		}
+	} else {
+		// `go list`
+		listPkgs, err = env.goTool.ListPackages(env.dir, []string{"-deps"}, []string{opts.Package})
+		if err != nil {
+			return nil, err
+		}
+		// `go list -m` (fast: in-memory)
+		mainMods = make(map[string]struct{})
+		for _, pkg := range listPkgs {
+			if !pkg.DepOnly && pkg.Module != nil {
+				mainMods[pkg.Module.Path] = struct{}{}
+			}
+		}
+	}
+
+	// `go mod vendor`
+	fs := newFSCache(env)
+	vendors := make([]map[string][]byte, len(listPkgs))
+	vendorErrs := make([]error, len(listPkgs))
+	parallel(opts.Jobs, len(listPkgs), func(i int) {
+		pkg := listPkgs[i]
+		vendor := make(map[string][]byte)
+		if pkg.Module == nil {
+			// standard library
+			vendor["std/LICENSE"] = goLicense
+		} else {
+			// module
+			if _, isMainMod := mainMods[pkg.Module.Path]; isMainMod {
+				return
+			}
+			if opts.Package == "mod" {
+				vendorErrs[i] = fs.collectVendoredPkg(vendor, pkg)
+			} else {
+				vendorErrs[i] = fs.collectPkg(vendor, pkg)
+			}
+		}
+		vendors[i] = vendor
+	})
+	pkgFiles := make(map[string]map[string][]byte)
+	for i, pkg := range listPkgs {
+		// Report the first error in `go list` order, not whichever
+		// happened first.
+		if vendorErrs[i] != nil {
+			return nil, vendorErrs[i]
+		}
+		if vendors[i] != nil {
+			pkgFiles[pkg.ImportPath] = vendors[i]
+		}
+	}
+
+	// `go mod verify`, but for the files that we just read
+	var modSums map[string]string
+	var verifyWarnings []error
+	if opts.VerifySources != VerifyOff {
+		goSum, err := readGoSum(env, "go.sum")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		var verifyErrs []error
+		modSums, verifyErrs = verifyModuleSources(env, listPkgs, mainMods, pkgFiles, opts.Package == "mod", goSum, opts.Jobs)
+		if verifyWarnings, err = reportVerifyErrors(opts.VerifySources, verifyErrs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sort the packages so that if there's an error, which error
+	// the user sees is deterministic.
+	pkgNames := make([]string, 0, len(pkgFiles))
+	for pkgName := range pkgFiles {
+		pkgNames = append(pkgNames, pkgName)
+	}
+	sort.Strings(pkgNames)
+
+	pkgVersions := map[string]string{}
+	for _, pkg := range listPkgs {
+		if pkg.Module != nil {
+			pkgVersions[pkg.ImportPath] = pkg.Module.Version
+		}
+	}
+
+	return &scannedPackages{
+		goVersion:      goVersion,
+		listPkgs:       listPkgs,
+		mainMods:       mainMods,
+		pkgFiles:       pkgFiles,
+		pkgNames:       pkgNames,
+		pkgVersions:    pkgVersions,
+		modSums:        modSums,
+		verifyWarnings: verifyWarnings,
+	}, nil
+}
@@ -0,0 +1,247 @@
+package mkopensource
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+
+	"github.com/datawire/go-mkopensource/pkg/archive"
+	"github.com/datawire/go-mkopensource/pkg/golist"
+)
+
+// Licenses like the LGPL and MPL require that the "complete
+// corresponding source" of the library be made available, not just the
+// files that happen to get compiled in to a program.  This file
+// collects the whole of a module, the same way that `go mod download`
+// sees it.
+
+// readGoSum returns the "h1:" hashes of the module zips in a go.sum
+// file, keyed by "path version".  The hashes of go.mod files are
+// skipped.
+func readGoSum(env *env, filename string) (map[string]string, error) {
+	file, err := env.open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		sums[fields[0]+" "+fields[1]] = fields[2]
+	}
+	return sums, scanner.Err()
+}
+
+// collectModuleSource returns all of the files of a module, named with
+// the module path as a prefix (the same as the files in pkgFiles), and
+// a manifest entry describing them.
+func collectModuleSource(env *env, mod *golist.Module, goSum map[string]string) (map[string][]byte, archive.PackageSource, error) {
+	if mod.Replace != nil && mod.Replace.Version == "" {
+		return collectModuleDir(env, mod)
+	}
+	return collectModuleZip(env, mod, goSum)
+}
+
+// downloadModule runs `go mod download` for a module (which only
+// downloads it if it isn't already in the module cache), and checks
+// that the zip file is the one that go.sum says it should be.
+func downloadModule(env *env, srcPath, srcVersion string, goSum map[string]string) (ModuleDownload, error) {
+	srcID := srcPath + "@" + srcVersion
+
+	download, err := env.goTool.ModDownload(env.dir, srcID)
+	if err != nil {
+		return ModuleDownload{}, err
+	}
+
+	expectedSum, ok := goSum[srcPath+" "+srcVersion]
+	if !ok {
+		return ModuleDownload{}, fmt.Errorf("module %s is missing from go.sum", srcID)
+	}
+	// Don't just trust the "Sum" that `go mod download` reports; that
+	// is read from a file next to the zip, not from the zip itself.
+	if err := checkModuleZip(env, srcID, download.Zip, expectedSum); err != nil {
+		return ModuleDownload{}, err
+	}
+	download.Sum = expectedSum
+	return download, nil
+}
+
+// checkModuleZip checks that the zip file of the module srcID
+// ("path@version") has the hash that go.sum says it should.
+func checkModuleZip(env *env, srcID, zipFilename, expectedSum string) error {
+	zipFile, err := env.openZip(zipFilename)
+	if err != nil {
+		return err
+	}
+	zipSum, err := hashZip(zipFile)
+	if err != nil {
+		return err
+	}
+	if zipSum != expectedSum {
+		return fmt.Errorf("module %s: checksum mismatch: go.sum has %s, but %s has %s",
+			srcID, expectedSum, zipFilename, zipSum)
+	}
+	return nil
+}
+
+// moduleCacheZip returns the name of the zip file of a module in the
+// module cache gomodcache, where `go mod download` would put it.
+func moduleCacheZip(gomodcache, srcPath, srcVersion string) (string, error) {
+	escPath, err := module.EscapePath(srcPath)
+	if err != nil {
+		return "", err
+	}
+	escVersion, err := module.EscapeVersion(srcVersion)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gomodcache, "cache", "download", filepath.FromSlash(escPath), "@v", escVersion+".zip"), nil
+}
+
+// collectModuleZip collects a module from its zip file in the module
+// cache, after checking that the zip is the one that go.sum says it
+// should be.
+func collectModuleZip(env *env, mod *golist.Module, goSum map[string]string) (map[string][]byte, archive.PackageSource, error) {
+	srcPath, srcVersion := moduleSource(mod)
+	srcID := srcPath + "@" + srcVersion
+
+	download, err := downloadModule(env, srcPath, srcVersion, goSum)
+	if err != nil {
+		return nil, archive.PackageSource{}, err
+	}
+
+	files, err := readModuleZip(env, download.Zip, srcID, mod.Path)
+	if err != nil {
+		return nil, archive.PackageSource{}, err
+	}
+
+	source := archive.PackageSource{
+		Name:    mod.Path,
+		Version: mod.Version,
+		Sum:     download.Sum,
+		Origin: archive.Origin{
+			Source:   "module-cache",
+			Location: srcID,
+		},
+		Files: archive.HashFiles(files),
+	}
+	if download.Origin != nil {
+		source.Origin.VCS = download.Origin.VCS
+		source.Origin.URL = download.Origin.URL
+		source.Origin.Ref = download.Origin.Ref
+		source.Origin.Hash = download.Origin.Hash
+	}
+	return files, source, nil
+}
+
+// moduleSource returns the path and version that a module's source
+// code is actually fetched from, taking replacements in to account.
+func moduleSource(mod *golist.Module) (srcPath, srcVersion string) {
+	if mod.Replace != nil {
+		return mod.Replace.Path, mod.Replace.Version
+	}
+	return mod.Path, mod.Version
+}
+
+// readModuleZip reads all of the files in the zip file of the module
+// srcID ("path@version"), naming them with dst as a prefix instead.
+func readModuleZip(env *env, zipFilename, srcID, dst string) (map[string][]byte, error) {
+	zipFile, err := env.openZip(zipFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := srcID + "/"
+	files := make(map[string][]byte)
+	for _, file := range zipFile.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if !strings.HasPrefix(file.Name, prefix) {
+			return nil, fmt.Errorf("module %s: unexpected file %q in %s", srcID, file.Name, zipFilename)
+		}
+		body, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Join(dst, strings.TrimPrefix(file.Name, prefix))] = body
+	}
+	return files, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// collectModuleDir collects a module that has been replaced with a
+// directory on disk.  Like a module zip, it leaves out VCS metadata
+// and nested modules.
+func collectModuleDir(env *env, mod *golist.Module) (map[string][]byte, archive.PackageSource, error) {
+	dir := mod.Replace.Dir
+	if dir == "" {
+		dir = mod.Replace.Path
+	}
+	root := env.fsName(dir)
+
+	files := make(map[string][]byte)
+	err := fs.WalkDir(env.fsys, root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if name == root {
+				return nil
+			}
+			switch entry.Name() {
+			case ".bzr", ".git", ".hg", ".svn":
+				return fs.SkipDir
+			}
+			if _, err := fs.Stat(env.fsys, path.Join(name, "go.mod")); err == nil {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		body, err := fs.ReadFile(env.fsys, name)
+		if err != nil {
+			return err
+		}
+		files[path.Join(mod.Path, relName(root, name))] = body
+		return nil
+	})
+	if err != nil {
+		return nil, archive.PackageSource{}, err
+	}
+
+	return files, archive.PackageSource{
+		Name:    mod.Path,
+		Version: mod.Version,
+		Origin: archive.Origin{
+			Source:   "directory",
+			Location: mod.Replace.Path,
+		},
+		Files: archive.HashFiles(files),
+	}, nil
+}
@@ -1,12 +1,10 @@
-package main
+package mkopensource
 
 import (
 	"bufio"
 	"errors"
 	"fmt"
-	"log"
-	"os"
-	"os/exec"
+	"io/fs"
 	"path"
 	"strings"
 
@@ -20,21 +18,25 @@ import (
 // consider dependencies of a specific package rather than the whole
 // module.
 func VendorList() ([]golist.Package, error) {
+	env, err := newEnv(Options{})
+	if err != nil {
+		return nil, err
+	}
+	return vendorList(env)
+}
+
+// vendorList is VendorList, for the module in env.
+func vendorList(env *env) ([]golist.Package, error) {
 	// References: In the Go stdlib source code, see
 	// - `cmd/go/internal/modcmd/vendor.go` for the code that writes modules.txt, and
 	// - `cmd/go/internal/modload/vendor.go` for the code that parses it.
-	cmd := exec.Command("go", "mod", "vendor")
-	if out, err := cmd.CombinedOutput(); err != nil {
-		errInstall := findAndGetDependencies(string(out))
-		if errInstall == nil {
-			return VendorList()
-		}
-		return nil, fmt.Errorf("%q: %w", []string{"go", "mod", "vendor"}, err)
+	if err := env.goTool.ModVendor(env.dir); err != nil {
+		return nil, err
 	}
 
-	file, err := os.Open("vendor/modules.txt")
+	file, err := env.open("vendor/modules.txt")
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			// If there are no dependencies outside of stdlib.
 			return nil, nil
 		}
@@ -81,7 +83,7 @@ func VendorList() ([]golist.Package, error) {
 			curModule = nil
 		} else {
 			if curModule == nil && curModuleName != "" {
-				modules, err := golist.GoListModules([]string{"-mod=vendor"}, []string{curModuleName})
+				modules, err := env.goTool.ListModules(env.dir, []string{"-mod=vendor"}, []string{curModuleName})
 				if err != nil {
 					return nil, err
 				}
@@ -103,27 +105,3 @@ func VendorList() ([]golist.Package, error) {
 
 	return pkgs, nil
 }
-
-func findAndGetDependencies(outputFromModVendor string) error {
-	lines := strings.Split(outputFromModVendor, "\n")
-	var dependenciesToInstall []string
-	for _, line := range lines {
-		if strings.Contains(line, "go get") {
-			dependenciesToInstall = append(dependenciesToInstall, line)
-		}
-	}
-	if len(dependenciesToInstall) <= 0 {
-		log.Println(outputFromModVendor)
-		return fmt.Errorf("none dependency required installation")
-	}
-	for _, dependency := range dependenciesToInstall {
-		command := strings.Split(strings.TrimSpace(dependency), " ")
-		cmd := exec.Command(command[0], command[1:]...)
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			log.Printf("Error installing dependency %v", err)
-			return fmt.Errorf("%q: %w", []string{"go", "mod", "vendor"}, err)
-		}
-	}
-	return nil
-}
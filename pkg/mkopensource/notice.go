@@ -0,0 +1,86 @@
+package mkopensource
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
+)
+
+// noticeFile is a NOTICE file of a dependency.
+type noticeFile struct {
+	Name string
+	Body []byte
+}
+
+// dependencyNotices are the NOTICE files of a single dependency.
+type dependencyNotices struct {
+	Name    string
+	Version string
+	Files   []noticeFile
+}
+
+func isNoticeFile(filename string) bool {
+	return strings.HasPrefix(filepath.Base(filename), "NOTICE")
+}
+
+// collectNotices adds the NOTICE files among files to notices.  The same
+// NOTICE file usually gets vendored along with each package of a module,
+// so files with the same contents as one that is already in notices are
+// skipped.
+func collectNotices(notices []noticeFile, files map[string][]byte) []noticeFile {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		if isNoticeFile(filename) {
+			filenames = append(filenames, filename)
+		}
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		found := false
+		for _, existing := range notices {
+			if bytes.Equal(existing.Body, files[filename]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			notices = append(notices, noticeFile{Name: filename, Body: files[filename]})
+		}
+	}
+	return notices
+}
+
+// noticeOutput writes a single document with the NOTICE files of all of
+// the dependencies.  Section 4(d) of the Apache License 2.0 requires
+// that the contents of a NOTICE file be redistributed "within a NOTICE
+// text file distributed as part of the Derivative Works", so this is
+// meant to be shipped as-is next to binaries and in container images.
+func noticeOutput(notices []dependencyNotices) *bytes.Buffer {
+	output := new(bytes.Buffer)
+	output.WriteString("THIRD-PARTY SOFTWARE NOTICES\n\n")
+
+	if len(notices) == 0 {
+		output.WriteString(scanningerrors.Wordwrap(0, 75, "None of the Free and Open Source software incorporated in this software has NOTICE files.") + "\n")
+		return output
+	}
+	output.WriteString(scanningerrors.Wordwrap(0, 75, "The following notices are reproduced from the NOTICE files of the Free and Open Source software incorporated in this software, as required by their licenses.") + "\n")
+
+	separator := strings.Repeat("=", 75) + "\n"
+	for _, dependency := range notices {
+		for _, file := range dependency.Files {
+			output.WriteString("\n")
+			output.WriteString(separator)
+			_, _ = fmt.Fprintf(output, "%s %s (%s)\n", dependency.Name, dependency.Version, file.Name)
+			output.WriteString(separator)
+			output.WriteString("\n")
+			output.Write(bytes.TrimRight(file.Body, "\n"))
+			output.WriteString("\n")
+		}
+	}
+	return output
+}
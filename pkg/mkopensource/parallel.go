@@ -0,0 +1,27 @@
+package mkopensource
+
+import (
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// parallel calls fn(i) for each i from 0 to n-1, on up to jobs
+// goroutines at a time (or runtime.NumCPU(), if jobs isn't positive),
+// and waits for them all.  fn must only write to the i'th element of
+// any shared results, so that the results don't depend on the order
+// that the calls finish in.
+func parallel(jobs, n int, fn func(i int)) {
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+	var group errgroup.Group
+	group.SetLimit(jobs)
+	for i := 0; i < n; i++ {
+		group.Go(func() error {
+			fn(i)
+			return nil
+		})
+	}
+	_ = group.Wait()
+}
@@ -0,0 +1,274 @@
+package mkopensource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/datawire/go-mkopensource/pkg/archive"
+	"github.com/datawire/go-mkopensource/pkg/dependencies"
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
+)
+
+// The renderers below write the reports of a Result.  They don't check
+// the Result's Errors; a report of a scan with errors is incomplete.
+
+// WriteMarkdown writes the Markdown table of the dependencies and their
+// licenses (DEPENDENCIES.md).
+func (r *Result) WriteMarkdown(w io.Writer) error {
+	output := new(bytes.Buffer)
+	markdownHeader(r, output)
+	output.WriteString("\n")
+	if err := markdownOutput(output, r.Dependencies); err != nil {
+		return err
+	}
+	_, err := output.WriteTo(w)
+	return err
+}
+
+// WriteAttribution writes the copyright notices and licenses of each
+// dependency.
+func (r *Result) WriteAttribution(w io.Writer) error {
+	output := new(bytes.Buffer)
+	markdownHeader(r, output)
+	output.WriteString("\n")
+	if err := attributionOutput(output, r.Dependencies); err != nil {
+		return err
+	}
+	_, err := output.WriteTo(w)
+	return err
+}
+
+// WriteJSON writes the Dependencies as JSON.
+func (r *Result) WriteJSON(w io.Writer) error {
+	output := new(bytes.Buffer)
+	if err := jsonOutput(output, r.Dependencies); err != nil {
+		return err
+	}
+	_, err := output.WriteTo(w)
+	return err
+}
+
+// WriteHTML writes a self-contained HTML page of the dependencies and
+// their licenses.  internalUseLabel is how to describe the licenses
+// that are InternalUseOnly, such as a Profile's InternalUseLabel.
+func (r *Result) WriteHTML(w io.Writer, internalUseLabel string) error {
+	header := new(bytes.Buffer)
+	markdownHeader(r, header)
+
+	output := new(bytes.Buffer)
+	if err := htmlOutput(output, strings.Join(strings.Fields(header.String()), " "), r.Dependencies, internalUseLabel); err != nil {
+		return err
+	}
+	_, err := output.WriteTo(w)
+	return err
+}
+
+// WriteNotice writes the NOTICE files of all of the dependencies.
+func (r *Result) WriteNotice(w io.Writer) error {
+	var notices []dependencyNotices
+	for _, mod := range r.Modules {
+		if len(mod.notices) == 0 || mod.Proprietary() {
+			continue
+		}
+		notices = append(notices, dependencyNotices{
+			Name:    mod.Name,
+			Version: mod.Version,
+			Files:   mod.notices,
+		})
+	}
+	_, err := noticeOutput(notices).WriteTo(w)
+	return err
+}
+
+// WriteThirdPartyLicenses writes the full text of the licenses of all
+// of the dependencies (THIRD_PARTY_LICENSES).
+func (r *Result) WriteThirdPartyLicenses(w io.Writer) error {
+	_, err := thirdPartyLicensesOutput(r.thirdPartyLicenseTexts()).WriteTo(w)
+	return err
+}
+
+// WriteThirdPartyLicensesHTML writes the same document as
+// WriteThirdPartyLicenses, as a self-contained HTML page.
+func (r *Result) WriteThirdPartyLicensesHTML(w io.Writer) error {
+	output := new(bytes.Buffer)
+	if err := thirdPartyLicensesHTMLOutput(output, r.thirdPartyLicenseTexts()); err != nil {
+		return err
+	}
+	_, err := output.WriteTo(w)
+	return err
+}
+
+func (r *Result) thirdPartyLicenseTexts() []dependencyLicenseTexts {
+	var deps []dependencyLicenseTexts
+	for _, mod := range r.Modules {
+		if mod.Proprietary() {
+			continue
+		}
+		deps = append(deps, dependencyLicenseTexts{
+			Name:       mod.Name,
+			Version:    mod.Version,
+			Licenses:   sortedLicenses(mod.Licenses),
+			Copyrights: mod.Copyrights,
+			Texts:      mod.licenseTexts,
+		})
+	}
+	return deps
+}
+
+// ArchiveFiles returns the files of the .opensource.tar.gz archive, for
+// archive.WriteTar or archive.WriteZip: DEPENDENCIES.md, and the license
+// files of each dependency, or all of the files of the weak-copyleft
+// ones.  If completeSource, it also has the complete source of each of
+// the weak-copyleft modules, and an archive.ManifestFilename manifest of
+// it.
+func (r *Result) ArchiveFiles(completeSource bool) (map[string][]byte, error) {
+	readme := new(bytes.Buffer)
+	if err := r.WriteMarkdown(readme); err != nil {
+		return nil, err
+	}
+	readme.WriteString("\n")
+	readme.WriteString(scanningerrors.Wordwrap(0, 75, "The appropriate license notices and source code are in correspondingly named directories.") + "\n")
+
+	tarFiles := make(map[string][]byte)
+	tarFiles["DEPENDENCIES.md"] = readme.Bytes()
+	for pkgName := range r.PackageFiles {
+		switch {
+		case isFirstPartyProprietary(r.PackageLicenses[pkgName]):
+			// don't include anything
+		case licenseIsWeakCopyleft(r.PackageLicenses[pkgName]):
+			// include everything
+			for filename, fileBody := range r.PackageFiles[pkgName] {
+				tarFiles[filename] = fileBody
+			}
+		default:
+			// just include metadata
+			for filename, fileBody := range r.PackageFiles[pkgName] {
+				if matchMetadata(filename) {
+					tarFiles[filename] = fileBody
+				}
+			}
+		}
+	}
+
+	if completeSource {
+		manifest, err := collectCompleteSource(r.env, tarFiles, r.Modules)
+		if err != nil {
+			return nil, err
+		}
+		tarFiles[archive.ManifestFilename] = manifest
+	}
+	return tarFiles, nil
+}
+
+// collectCompleteSource adds the complete source of each of the
+// weak-copyleft modules to tarFiles, and returns the manifest of them.
+func collectCompleteSource(env *env, tarFiles map[string][]byte, modules []Module) ([]byte, error) {
+	goSum, err := readGoSum(env, "go.sum")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var manifest archive.Manifest
+	for _, mod := range modules {
+		if mod.Info == nil || mod.Proprietary() || !licenseIsWeakCopyleft(mod.Licenses) {
+			continue
+		}
+		files, source, err := collectModuleSource(env, mod.Info, goSum)
+		if err != nil {
+			return nil, err
+		}
+		for filename, body := range files {
+			tarFiles[filename] = body
+		}
+		manifest.Packages = append(manifest.Packages, source)
+	}
+	return manifest.Marshal()
+}
+
+func markdownHeader(r *Result, readme *bytes.Buffer) {
+	if r.Package == "mod" {
+		if len(r.MainModules) == 1 {
+			readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The Go module %q incorporates the following Free and Open Source software:", r.MainModules[0])) + "\n")
+		} else {
+			readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The Go modules %q incorporate the following Free and Open Source software:", r.MainModules)) + "\n")
+		}
+		return
+	}
+
+	if len(r.MainLibPackages) == 0 {
+		if len(r.MainCmdPackages) == 1 {
+			readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The program %q incorporates the following Free and Open Source software:", path.Base(r.MainCmdPackages[0]))) + "\n")
+		} else {
+			readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The programs %q incorporate the following Free and Open Source software:", r.Package)) + "\n")
+		}
+		return
+	}
+
+	if len(r.MainLibPackages) == 1 {
+		readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The Package %q incorporates the following Free and Open Source software:", r.MainLibPackages[0])) + "\n")
+	} else {
+		readme.WriteString(scanningerrors.Wordwrap(0, 75, fmt.Sprintf("The Packages %q incorporate the following Free and Open Source software:", r.Package)) + "\n")
+	}
+}
+
+func markdownOutput(readme *bytes.Buffer, dependencyList dependencies.DependencyInfo) error {
+	table := tabwriter.NewWriter(readme, 0, 8, 2, ' ', 0)
+	_, _ = io.WriteString(table, "  \tName\tVersion\tLicense(s)\n")
+	_, _ = io.WriteString(table, "  \t----\t-------\t----------\n")
+
+	for _, dependency := range dependencyList.Dependencies {
+		depLicenses := strings.Join(dependency.Licenses, ", ")
+		if depLicenses == "" {
+			panic(fmt.Errorf("this should not happen: empty license string for %q", dependency.Name))
+		}
+
+		_, _ = fmt.Fprintf(table, "\t%s\t%s\t%s\n", dependency.Name, dependency.Version, depLicenses)
+	}
+	_ = table.Flush()
+	return nil
+}
+
+// attributionOutput writes the copyright notices and licenses of each
+// dependency, which is what the attribution requirements of most
+// permissive licenses ask for.
+func attributionOutput(readme *bytes.Buffer, dependencyList dependencies.DependencyInfo) error {
+	for i, dependency := range dependencyList.Dependencies {
+		if i > 0 {
+			readme.WriteString("\n")
+		}
+		_, _ = fmt.Fprintf(readme, "## %s %s\n\n", dependency.Name, dependency.Version)
+
+		for _, licenseName := range dependency.Licenses {
+			if url := dependencyList.Licenses[licenseName]; url != "" {
+				_, _ = fmt.Fprintf(readme, "License: %s <%s>\n", licenseName, url)
+			} else {
+				_, _ = fmt.Fprintf(readme, "License: %s\n", licenseName)
+			}
+		}
+		readme.WriteString("\n")
+
+		if len(dependency.Copyrights) == 0 {
+			readme.WriteString("    (no copyright notice in the license files)\n")
+		}
+		for _, copyright := range dependency.Copyrights {
+			_, _ = fmt.Fprintf(readme, "    %s\n", copyright)
+		}
+	}
+	return nil
+}
+
+func jsonOutput(readme *bytes.Buffer, dependencyList dependencies.DependencyInfo) error {
+	jsonString, err := json.Marshal(dependencyList)
+	if err != nil {
+		return fmt.Errorf("could not generate JSON output: %w", err)
+	}
+
+	readme.Write(jsonString)
+	return nil
+}
@@ -0,0 +1,512 @@
+// Package mkopensource scans the dependencies of a Go program or module
+// for their licenses, and renders the reports that go-mkopensource
+// writes; it is go-mkopensource without the command line.
+//
+// A scan uses the license database and the Profile of its Options,
+// rather than detectlicense.SetDatabase and Profile.Apply, so that
+// Scanners with different ones can run in the same process.
+package mkopensource
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/datawire/go-mkopensource/pkg/dependencies"
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+	"github.com/datawire/go-mkopensource/pkg/golist"
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
+)
+
+const (
+	// Where the scanned software runs; see Options.ApplicationType.
+	//
+	// The only validation for "internal" is to check that forbidden
+	// licenses are not used.
+	InternalApplication = "internal"
+	// "external" applications have additional license requirements as
+	// documented in the license policy of the Profile.
+	ExternalApplication = "external"
+)
+
+// Options are what to scan, and how.
+type Options struct {
+	// Package is the package(s) to scan: a `go list` pattern, or
+	// "mod" for every package that `go mod vendor` would vendor for
+	// the main module.  Packages are looked up in Dir.
+	Package string
+	// Dir is the directory of the module to scan; if empty, the
+	// current directory.  The other filenames in the Options are
+	// relative to the current directory, not to Dir.
+	Dir string
+	// FS is the filesystem that the files of the packages, go.sum and
+	// the module cache are read from.  Its names are absolute paths
+	// without the leading "/"; if nil, it is os.DirFS("/").
+	FS fs.FS
+	// GoTool runs the go command; if nil, the go command in $PATH is
+	// run.
+	GoTool GoTool
+	// VCS says whether go.mod and go.sum have uncommitted changes,
+	// for GoModDirty; if nil, Git.
+	VCS VCS
+	// GoTarFilename is the go1.*.src.tar.gz tarball of the Go
+	// standard library; the version and license of the standard
+	// library are read from it.  If it is empty, they are GoVersion and
+	// the contents of GoLicenseFile.
+	GoTarFilename string
+	// GoVersion is the release version of the Go standard library,
+	// such as "go1.23.6", "v1.23.6" or "go1.21rc2"; if empty, it is
+	// `go env GOVERSION` of the GoTool, or the toolchain directive of
+	// go.mod if that is newer.  Only valid without GoTarFilename.
+	GoVersion string
+	// GoLicenseFile is the LICENSE file of the Go standard library; if
+	// empty, it is $(go env GOROOT)/LICENSE of the GoTool, read from
+	// FS.  Only valid without GoTarFilename.
+	GoLicenseFile string
+	// ApplicationType is where the scanned software runs; one of
+	// InternalApplication or ExternalApplication.  An empty
+	// ApplicationType is ExternalApplication.
+	ApplicationType string
+	// UnparsablePackages is the --unparsable-packages file with the
+	// licenses of the packages whose license can't be detected, or
+	// empty if there isn't one.
+	UnparsablePackages string
+	// Database is the license database that detection uses; if nil,
+	// detectlicense.DefaultDatabase().
+	Database *detectlicense.Database
+	// Profile is the organization whose license policy the
+	// explanations of the errors refer to; if nil, the
+	// detectlicense.DefaultProfile.
+	Profile *detectlicense.Profile
+	// ProprietarySoftware are the packages that have the
+	// Profile.ProprietaryLicense(); if nil, Profile.Proprietary().
+	ProprietarySoftware detectlicense.ProprietarySoftware
+	// VerifySources is what to do if the files of a module don't match
+	// go.sum; one of VerifyError, VerifyWarn or VerifyOff.  An empty
+	// VerifySources is VerifyError.  A vendored module that can't be
+	// verified without the network, when the network is ruled out, is
+	// only ever a warning.
+	VerifySources string
+	// Cache is the cache of detected licenses, or nil to not use one.
+	Cache *detectlicense.Cache
+	// Jobs is how many packages to read and detect the licenses of at
+	// once; if it isn't positive, runtime.NumCPU().
+	Jobs int
+	// Evidence is whether Result.Dependencies includes the evidence
+	// for each license.
+	Evidence bool
+}
+
+// A Scanner scans the licenses of the dependencies of Go packages.
+type Scanner struct {
+	opts Options
+	env  *env
+}
+
+// withDefaults returns opts with the Database, Profile,
+// ProprietarySoftware and VerifySources filled in.
+func (opts Options) withDefaults() (Options, error) {
+	if opts.Database == nil {
+		opts.Database = detectlicense.DefaultDatabase()
+	}
+	if opts.Profile == nil {
+		profile, err := detectlicense.LoadProfile(detectlicense.DefaultProfile)
+		if err != nil {
+			return opts, err
+		}
+		opts.Profile = profile
+	}
+	if opts.ProprietarySoftware == nil {
+		opts.ProprietarySoftware = opts.Profile.Proprietary()
+	}
+	if opts.VerifySources == "" {
+		opts.VerifySources = VerifyError
+	}
+	return opts, nil
+}
+
+// NewScanner returns a Scanner with the given options, or an error if
+// they aren't valid.
+func NewScanner(opts Options) (*Scanner, error) {
+	if opts.Package == "" {
+		return nil, errors.New("no package to scan")
+	}
+	if opts.GoTarFilename != "" {
+		if !strings.HasPrefix(filepath.Base(opts.GoTarFilename), "go1.") || !strings.HasSuffix(opts.GoTarFilename, ".tar.gz") {
+			return nil, fmt.Errorf("GoTarFilename (%q) doesn't look like a go1.*.tar.gz file", opts.GoTarFilename)
+		}
+		if opts.GoVersion != "" || opts.GoLicenseFile != "" {
+			return nil, errors.New("GoVersion and GoLicenseFile are only valid without GoTarFilename")
+		}
+	}
+	if opts.GoVersion != "" {
+		if _, err := parseGoVersion(opts.GoVersion); err != nil {
+			return nil, fmt.Errorf("GoVersion: %w", err)
+		}
+	}
+	switch opts.ApplicationType {
+	case "", InternalApplication, ExternalApplication:
+	default:
+		return nil, fmt.Errorf("ApplicationType must be one of '%s', '%s'", InternalApplication, ExternalApplication)
+	}
+	switch opts.VerifySources {
+	case "", VerifyError, VerifyWarn, VerifyOff:
+	default:
+		return nil, fmt.Errorf("VerifySources must be one of '%s', '%s', '%s'", VerifyError, VerifyWarn, VerifyOff)
+	}
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	env, err := newEnv(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Scanner{opts: opts, env: env}, nil
+}
+
+// GoModDirty returns whether the go.mod or go.sum of the scanned module
+// have uncommitted changes; for instance, because Scan tidied them.  A
+// report is only meaningful when it is committed along with the go.mod
+// and go.sum that it was made from.
+func (s *Scanner) GoModDirty() (bool, error) {
+	return s.env.vcs.Modified(s.env.dir, "go.mod", "go.sum")
+}
+
+// A Result is the outcome of a scan.
+type Result struct {
+	// Package is the Options.Package that was scanned.
+	Package string
+	// GoVersion is the version of the Go standard library.
+	GoVersion string
+	// MainModules are the modules that the scanned packages are in,
+	// sorted; MainLibPackages and MainCmdPackages are the scanned
+	// library and program packages, sorted.
+	MainModules     []string
+	MainLibPackages []string
+	MainCmdPackages []string
+
+	// PackageFiles are the files of each dependency package, as they
+	// would be vendored; PackageLicenses are its licenses.  A package
+	// whose license couldn't be detected has no PackageLicenses.
+	PackageFiles    map[string]map[string][]byte
+	PackageLicenses map[string]map[detectlicense.License]struct{}
+	// Modules are the dependency modules, sorted by their Key.
+	Modules []Module
+	// Dependencies is the report of the Modules that aren't the
+	// organization's own, as written by WriteJSON.
+	Dependencies dependencies.DependencyInfo
+
+	// Errors are the problems with the licenses, which should fail the
+	// scan: licenses that couldn't be detected or aren't allowed, and
+	// stale --unparsable-packages entries.  They are the error types
+	// of scanningerrors; see ExplainErrors.
+	Errors []error
+	// Warnings are problems that don't fail the scan: the modules that
+	// don't match go.sum, if Options.VerifySources is VerifyWarn.
+	Warnings []error
+
+	env    *env
+	policy scanningerrors.Policy
+}
+
+// ExplainErrors combines the Errors in to a single error, as
+// scanningerrors.ExplainErrors does, with explanations that refer to the
+// license policy of the Options.Profile; or returns nil if there are
+// none.
+func (r *Result) ExplainErrors() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return r.policy.ExplainErrors(r.Errors)
+}
+
+// ErrorReport is the machine-readable version of ExplainErrors, as
+// scanningerrors.NewReport builds.  locate may be nil.
+func (r *Result) ErrorReport(locate scanningerrors.Locator) scanningerrors.Report {
+	return r.policy.NewReport(r.Errors, locate)
+}
+
+// A Module is a dependency module, with the licenses of its packages.
+type Module struct {
+	// Key is the path of the module, or "<nil>" for the Go standard
+	// library.
+	Key string
+	// Info is what `go list` says about the module; it is nil for the
+	// Go standard library.
+	Info *golist.Module
+	// Name and Version are how the module is named in the reports.
+	Name    string
+	Version string
+	// Packages are the packages of the module that are used.
+	Packages []string
+	// Licenses are the licenses of all of the Packages, and Evidence
+	// is where each of them was detected.
+	Licenses map[detectlicense.License]struct{}
+	Evidence detectlicense.Detection
+	// Copyrights are the copyright notices in the license files.
+	Copyrights []string
+	// Sum is the go.sum hash of the module, if it was verified.
+	Sum string
+
+	notices      []noticeFile
+	licenseTexts []licenseText
+}
+
+// Proprietary returns whether the module is the organization's own
+// software, which isn't included in the reports.
+func (m *Module) Proprietary() bool {
+	return isFirstPartyProprietary(m.Licenses)
+}
+
+// Scan reads the packages, and detects their licenses.  Scan runs
+// `go mod tidy` (and, for "mod", `go mod vendor`) in Options.Dir.  It
+// only returns an error if the packages can't be read; problems with
+// their licenses are in the Result's Errors.
+func (s *Scanner) Scan() (*Result, error) {
+	opts := s.opts
+
+	pkgs, err := loadPackages(s.env, opts)
+	if err != nil {
+		return nil, err
+	}
+	goVersion, listPkgs, mainMods := pkgs.goVersion, pkgs.listPkgs, pkgs.mainMods
+	pkgFiles, pkgNames, pkgVersions, modSums := pkgs.pkgFiles, pkgs.pkgNames, pkgs.pkgVersions, pkgs.modSums
+
+	// From this point on, everything should be entirely in-memory.
+
+	// Figure out the license(s) that apply to each package.
+	pkgLicenses := make(map[string]map[detectlicense.License]struct{})
+	pkgEvidence := make(map[string]detectlicense.Detection)
+	licErrs := []error(nil)
+
+	var unparsablePackages map[string]map[detectlicense.License]struct{}
+	var unparsableEvidence detectlicense.Evidence
+	if opts.UnparsablePackages != "" {
+		if unparsablePackages, err = detectlicense.ReadPackageLicensesFromFile(opts.UnparsablePackages); err != nil {
+			return nil, err
+		}
+		unparsableEvidence = detectlicense.Evidence{Source: detectlicense.EvidenceOverride, File: opts.UnparsablePackages}
+		if body, err := os.ReadFile(opts.UnparsablePackages); err == nil {
+			unparsableEvidence = detectlicense.NewEvidence(detectlicense.EvidenceOverride, opts.UnparsablePackages, body)
+		}
+	}
+
+	proprietarySoftware := opts.ProprietarySoftware
+
+	// Detect the licenses of all of the packages at once, and then go
+	// through the results in order, so that the errors are in the same
+	// order as if they had been detected one at a time.
+	// A package whose module was verified against go.sum is cached by
+	// the go.sum hash.
+	pkgSums := make(map[string]string)
+	for _, pkg := range listPkgs {
+		if pkg.Module != nil {
+			pkgSums[pkg.ImportPath] = modSums[pkg.Module.Path]
+		}
+	}
+	detections := make([]detectlicense.Detection, len(pkgNames))
+	detectionErrs := make([]error, len(pkgNames))
+	parallel(opts.Jobs, len(pkgNames), func(i int) {
+		pkgName := pkgNames[i]
+		if proprietarySoftware.IsProprietarySoftware(pkgName) {
+			return
+		}
+		detections[i], detectionErrs[i] = opts.Cache.DetectLicensesWithEvidence(opts.Database, pkgName, pkgVersions[pkgName], pkgSums[pkgName], pkgFiles[pkgName])
+	})
+
+	for i, pkgName := range pkgNames {
+		if proprietarySoftware.IsProprietarySoftware(pkgName) {
+			// The organization's own software has a proprietary license
+			pkgLicenses[pkgName] = map[detectlicense.License]struct{}{opts.Profile.ProprietaryLicense(): {}}
+			continue
+		}
+
+		detection, err := detections[i], detectionErrs[i]
+		if err != nil {
+			if licenses, ok := unparsablePackages[pkgName]; ok {
+				pkgLicenses[pkgName] = licenses
+				pkgEvidence[pkgName] = detectlicense.NewDetection(licenses, unparsableEvidence)
+			} else {
+				err = fmt.Errorf(`Package %q: %w`, pkgName, err)
+				licErrs = append(licErrs, err)
+			}
+		} else {
+			pkgLicenses[pkgName] = detection.Licenses()
+			pkgEvidence[pkgName] = detection
+			if _, ok := unparsablePackages[pkgName]; ok {
+				licErrs = append(licErrs, &scanningerrors.StaleOverrideError{
+					Name:    pkgName,
+					Version: pkgVersions[pkgName],
+					File:    opts.UnparsablePackages,
+				})
+			}
+		}
+	}
+
+	// Group packages by module & collect module info
+	modIndex := make(map[string]int)
+	var modules []Module
+	for _, pkg := range listPkgs {
+		key := "<nil>"
+		if pkg.Module != nil {
+			key = pkg.Module.Path
+		}
+		if _, isMainMod := mainMods[key]; isMainMod {
+			continue
+		}
+		i, done := modIndex[key]
+		if !done {
+			i = len(modules)
+			modIndex[key] = i
+			modules = append(modules, Module{
+				Key:      key,
+				Info:     pkg.Module,
+				Name:     getDependencyName(pkg.Module),
+				Version:  getDependencyVersion(pkg.Module, goVersion),
+				Licenses: make(map[detectlicense.License]struct{}),
+				Evidence: make(detectlicense.Detection),
+				Sum:      modSums[key],
+			})
+		}
+		mod := &modules[i]
+		mod.Packages = append(mod.Packages, pkg.ImportPath)
+		for license := range pkgLicenses[pkg.ImportPath] {
+			mod.Licenses[license] = struct{}{}
+		}
+		mod.Evidence.Merge(pkgEvidence[pkg.ImportPath])
+		mod.Copyrights = collectCopyrights(mod.Copyrights, pkgFiles[pkg.ImportPath])
+		mod.notices = collectNotices(mod.notices, pkgFiles[pkg.ImportPath])
+		mod.licenseTexts = collectLicenseTexts(mod.licenseTexts, pkgFiles[pkg.ImportPath])
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Key < modules[j].Key
+	})
+	for i := range modules {
+		modules[i].licenseTexts = addCanonicalTexts(modules[i].licenseTexts, modules[i].Evidence)
+	}
+
+	// Figure out how to pronounce "X" in "X incorporates Free and
+	// Open Source software".
+	var mainCmdPkgs []string
+	var mainLibPkgs []string
+	for _, pkg := range listPkgs {
+		if pkg.Module == nil {
+			continue
+		}
+		if _, isMainMod := mainMods[pkg.Module.Path]; !isMainMod {
+			continue
+		}
+		if pkg.DepOnly {
+			continue
+		}
+		if pkg.Name == "main" {
+			mainCmdPkgs = append(mainCmdPkgs, pkg.ImportPath)
+		} else {
+			mainLibPkgs = append(mainLibPkgs, pkg.ImportPath)
+		}
+	}
+	sort.Strings(mainCmdPkgs)
+	sort.Strings(mainLibPkgs)
+	mainModNames := make([]string, 0, len(mainMods))
+	for modName := range mainMods {
+		mainModNames = append(mainModNames, modName)
+	}
+	sort.Strings(mainModNames)
+
+	modNames := make([]string, 0, len(modules))
+	modInfos := make(map[string]*golist.Module, len(modules))
+	modLicenses := make(map[string]map[detectlicense.License]struct{}, len(modules))
+	modEvidence := make(map[string]detectlicense.Detection, len(modules))
+	modCopyrights := make(map[string][]string, len(modules))
+	for _, mod := range modules {
+		modNames = append(modNames, mod.Key)
+		modInfos[mod.Key] = mod.Info
+		modLicenses[mod.Key] = mod.Licenses
+		modEvidence[mod.Key] = mod.Evidence
+		modCopyrights[mod.Key] = mod.Copyrights
+	}
+	if !opts.Evidence {
+		modEvidence = nil
+	}
+	dependencyList, licenseErrors := GenerateDependencyList(modNames, modLicenses, modEvidence, modCopyrights, modSums, modInfos, goVersion,
+		getLicenseRestriction(opts.ApplicationType))
+	licErrs = append(licErrs, licenseErrors...)
+
+	return &Result{
+		Package:         opts.Package,
+		GoVersion:       goVersion,
+		MainModules:     mainModNames,
+		MainLibPackages: mainLibPkgs,
+		MainCmdPackages: mainCmdPkgs,
+		PackageFiles:    pkgFiles,
+		PackageLicenses: pkgLicenses,
+		Modules:         modules,
+		Dependencies:    dependencyList,
+		Errors:          licErrs,
+		Warnings:        pkgs.verifyWarnings,
+		env:             s.env,
+		policy:          opts.Profile.Policy(),
+	}, nil
+}
+
+func isFirstPartyProprietary(licenses map[detectlicense.License]struct{}) bool {
+	for license := range licenses {
+		if license.Proprietary {
+			return true
+		}
+	}
+	return false
+}
+
+func licenseIsWeakCopyleft(licenses map[detectlicense.License]struct{}) bool {
+	for license := range licenses {
+		if license.WeakCopyleft {
+			return true
+		}
+	}
+	return false
+}
+
+func getLicenseRestriction(applicationType string) detectlicense.LicenseRestriction {
+	var LicenseRestriction detectlicense.LicenseRestriction
+	switch applicationType {
+	case InternalApplication:
+		LicenseRestriction = detectlicense.InternalUseOnly
+	default:
+		LicenseRestriction = detectlicense.Unrestricted
+	}
+	return LicenseRestriction
+}
+
+// collectCopyrights adds the copyright notices in the license files
+// among files to copyrights, skipping any that are already in it.
+func collectCopyrights(copyrights []string, files map[string][]byte) []string {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		if detectlicense.IsLicenseFile(filename) {
+			filenames = append(filenames, filename)
+		}
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		for _, copyright := range detectlicense.ExtractCopyrights(files[filename]) {
+			found := false
+			for _, existing := range copyrights {
+				if existing == copyright {
+					found = true
+					break
+				}
+			}
+			if !found {
+				copyrights = append(copyrights, copyright)
+			}
+		}
+	}
+	return copyrights
+}
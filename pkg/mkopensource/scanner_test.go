@@ -0,0 +1,140 @@
+package mkopensource_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/go-mkopensource/pkg/dependencies"
+	"github.com/datawire/go-mkopensource/pkg/mkopensource"
+)
+
+// The test programs are those of go-mkopensource's own tests.
+const testdata = "../../cmd/go-mkopensource/testdata"
+
+// chdirTestdata changes to a copy of one of the test programs, so that
+// scanning it (which vendors it) doesn't race with the go-mkopensource
+// tests scanning the same program.
+func chdirTestdata(t *testing.T, name string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.CopyFS(dir, os.DirFS(filepath.Join(testdata, name))))
+	workingDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(workingDir))
+	})
+}
+
+func goTar(t *testing.T) string {
+	t.Helper()
+	goTar, err := filepath.Abs(filepath.Join(testdata, "go1.17.3-testdata.src.tar.gz"))
+	require.NoError(t, err)
+	return goTar
+}
+
+func TestNewScanner(t *testing.T) {
+	testCases := map[string]mkopensource.Options{
+		"no package":             {GoTarFilename: "go1.17.3.src.tar.gz"},
+		"not a go tarball":       {Package: "mod", GoTarFilename: "go.tar.gz"},
+		"bad application type":   {Package: "mod", GoTarFilename: "go1.17.3.src.tar.gz", ApplicationType: "cloud"},
+		"bad verify sources":     {Package: "mod", GoTarFilename: "go1.17.3.src.tar.gz", VerifySources: "maybe"},
+		"go tarball and version": {Package: "mod", GoTarFilename: "go1.17.3.src.tar.gz", GoVersion: "go1.17.3"},
+		"go tarball and license": {Package: "mod", GoTarFilename: "go1.17.3.src.tar.gz", GoLicenseFile: "LICENSE"},
+		"bad go version":         {Package: "mod", GoVersion: "devel go1.24-0123456"},
+		"go language version":    {Package: "mod", GoVersion: "go1.23"},
+		"short module version":   {Package: "mod", GoVersion: "v1.23"},
+	}
+	for name, opts := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := mkopensource.NewScanner(opts)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := mkopensource.NewScanner(mkopensource.Options{Package: "mod", GoTarFilename: "go1.17.3.src.tar.gz"})
+	assert.NoError(t, err)
+	_, err = mkopensource.NewScanner(mkopensource.Options{Package: "mod", GoVersion: "1.23.6", GoLicenseFile: "LICENSE"})
+	assert.NoError(t, err)
+	_, err = mkopensource.NewScanner(mkopensource.Options{Package: "mod"})
+	assert.NoError(t, err)
+}
+
+func TestScan(t *testing.T) {
+	goTar := goTar(t)
+	chdirTestdata(t, "06-multiple-licenses")
+
+	scanner, err := mkopensource.NewScanner(mkopensource.Options{
+		Package:       "mod",
+		GoTarFilename: goTar,
+	})
+	require.NoError(t, err)
+	result, err := scanner.Scan()
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+
+	assert.Equal(t, "v1.17.3", result.GoVersion)
+	assert.Equal(t, []string{"testmod"}, result.MainModules)
+	var modules []string
+	for _, mod := range result.Modules {
+		modules = append(modules, mod.Key)
+		assert.NotEmpty(t, mod.Licenses, mod.Key)
+		assert.NotEmpty(t, mod.Evidence, mod.Key)
+	}
+	assert.True(t, sort.StringsAreSorted(modules))
+	assert.Contains(t, modules, "<nil>")
+	assert.Contains(t, modules, "github.com/josharian/intern")
+
+	var expected dependencies.DependencyInfo
+	require.NoError(t, json.Unmarshal(readFile(t, "expected_json_output.json"), &expected))
+	assert.Equal(t, expected, result.Dependencies)
+
+	renderers := map[string]func(*bytes.Buffer) error{
+		"expected_markdown_output.txt":              func(w *bytes.Buffer) error { return result.WriteMarkdown(w) },
+		"expected_attribution_output.txt":           func(w *bytes.Buffer) error { return result.WriteAttribution(w) },
+		"expected_notice_output.txt":                func(w *bytes.Buffer) error { return result.WriteNotice(w) },
+		"expected_third_party_licenses_output.txt":  func(w *bytes.Buffer) error { return result.WriteThirdPartyLicenses(w) },
+		"expected_third_party_licenses_output.html": func(w *bytes.Buffer) error { return result.WriteThirdPartyLicensesHTML(w) },
+	}
+	for filename, render := range renderers {
+		t.Run(filename, func(t *testing.T) {
+			output := new(bytes.Buffer)
+			require.NoError(t, render(output))
+			assert.Equal(t, string(readFile(t, filename)), output.String())
+		})
+	}
+}
+
+func TestScan_errors(t *testing.T) {
+	goTar := goTar(t)
+	chdirTestdata(t, "03-multierror")
+
+	scanner, err := mkopensource.NewScanner(mkopensource.Options{
+		Package:       "mod",
+		GoTarFilename: goTar,
+	})
+	require.NoError(t, err)
+	result, err := scanner.Scan()
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Errors)
+
+	// The expected errors are what go-mkopensource says with its
+	// default profile, which is the Scanner's default too.
+	explained := result.ExplainErrors()
+	assert.Equal(t, strings.TrimSpace(string(readFile(t, "expected_err.txt"))), strings.TrimSpace(explained.Error()))
+}
+
+func readFile(t *testing.T, filename string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	return body
+}
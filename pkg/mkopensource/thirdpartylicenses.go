@@ -0,0 +1,238 @@
+package mkopensource
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/datawire/go-mkopensource/pkg/detectlicense"
+	"github.com/datawire/go-mkopensource/pkg/scanningerrors"
+)
+
+// licenseText is the full text of a license that a dependency uses.
+type licenseText struct {
+	// Name is the license-file that the text came from, or, for a
+	// canonical text, the name of the license.
+	Name string
+	Body []byte
+}
+
+// dependencyLicenseTexts is everything that goes in to the
+// THIRD_PARTY_LICENSES document for a single dependency.
+type dependencyLicenseTexts struct {
+	Name       string
+	Version    string
+	Licenses   []detectlicense.License
+	Copyrights []string
+	Texts      []licenseText
+}
+
+// collectLicenseTexts adds the license files among files to texts,
+// skipping files with the same contents as one that is already in
+// texts.
+func collectLicenseTexts(texts []licenseText, files map[string][]byte) []licenseText {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		if detectlicense.IsLicenseFile(filename) {
+			filenames = append(filenames, filename)
+		}
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		if !containsLicenseText(texts, files[filename]) {
+			texts = append(texts, licenseText{Name: filename, Body: files[filename]})
+		}
+	}
+	return texts
+}
+
+// addCanonicalTexts adds the canonical text of each license in
+// detection that none of the texts collected by collectLicenseTexts
+// are the text of: licenses that were detected from SPDX tags or
+// license headers rather than from a license file (identified or
+// curated).  An override is usually there because the license file
+// couldn't be parsed, so overridden licenses only get the canonical
+// text if there are no license files at all.
+func addCanonicalTexts(texts []licenseText, detection detectlicense.Detection) []licenseText {
+	haveFiles := len(texts) > 0
+	for _, license := range sortedLicenses(detection.Licenses()) {
+		sources := make(map[detectlicense.EvidenceSource]struct{})
+		for _, evidence := range detection[license] {
+			sources[evidence.Source] = struct{}{}
+		}
+		if _, fromFile := sources[detectlicense.EvidenceLicenseFile]; fromFile {
+			continue
+		}
+		if _, fromCuration := sources[detectlicense.EvidenceCuration]; fromCuration {
+			continue
+		}
+		if _, fromOverride := sources[detectlicense.EvidenceOverride]; fromOverride && len(sources) == 1 && haveFiles {
+			continue
+		}
+		body, ok := detectlicense.CanonicalText(license)
+		if ok && !containsLicenseText(texts, body) {
+			texts = append(texts, licenseText{Name: license.Name + " (canonical text)", Body: body})
+		}
+	}
+	return texts
+}
+
+func containsLicenseText(texts []licenseText, body []byte) bool {
+	for _, text := range texts {
+		if bytes.Equal(text.Body, body) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedLicenses(licenses map[detectlicense.License]struct{}) []detectlicense.License {
+	list := make([]detectlicense.License, 0, len(licenses))
+	for license := range licenses {
+		list = append(list, license)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// numberLicenseTexts numbers the license texts of deps from 1, in the
+// order that they are first used, with identical texts sharing a
+// number.  It returns the distinct texts, and the numbers of the texts
+// of each dependency.
+func numberLicenseTexts(deps []dependencyLicenseTexts) (texts []licenseText, refs [][]int) {
+	textIndex := func(body []byte) int {
+		for i, text := range texts {
+			if bytes.Equal(text.Body, body) {
+				return i + 1
+			}
+		}
+		return 0
+	}
+
+	refs = make([][]int, len(deps))
+	for i, dependency := range deps {
+		for _, text := range dependency.Texts {
+			idx := textIndex(text.Body)
+			if idx == 0 {
+				texts = append(texts, text)
+				idx = len(texts)
+			}
+			refs[i] = append(refs[i], idx)
+		}
+	}
+	return texts, refs
+}
+
+// thirdPartyLicensesOutput writes a single document with the name,
+// version, copyright notices, and full license text of each of the
+// dependencies; this is what most licenses require to be distributed
+// along with binaries.  Dependencies that use the same license text
+// (which is very common for the Apache License 2.0) share a single copy
+// of it at the end of the document.
+func thirdPartyLicensesOutput(deps []dependencyLicenseTexts) *bytes.Buffer {
+	output := new(bytes.Buffer)
+	output.WriteString("THIRD-PARTY SOFTWARE LICENSES\n\n")
+
+	if len(deps) == 0 {
+		output.WriteString(scanningerrors.Wordwrap(0, 75, "This software does not incorporate any Free and Open Source software.") + "\n")
+		return output
+	}
+	output.WriteString(scanningerrors.Wordwrap(0, 75, "This software incorporates the following Free and Open Source software. The full text of each license is reproduced at the end of this document; dependencies that use identical license texts share a single copy.") + "\n")
+
+	texts, refs := numberLicenseTexts(deps)
+	for i, dependency := range deps {
+		output.WriteString("\n")
+		output.WriteString(strings.Repeat("-", 75) + "\n")
+		_, _ = fmt.Fprintf(output, "%s %s\n\n", dependency.Name, dependency.Version)
+
+		for _, license := range dependency.Licenses {
+			if license.URL != "" {
+				_, _ = fmt.Fprintf(output, "License: %s <%s>\n", license.Name, license.URL)
+			} else {
+				_, _ = fmt.Fprintf(output, "License: %s\n", license.Name)
+			}
+		}
+		for _, copyright := range dependency.Copyrights {
+			_, _ = fmt.Fprintf(output, "%s\n", copyright)
+		}
+
+		if len(refs[i]) == 0 {
+			output.WriteString("License text: not available; see the license URL above\n")
+		} else {
+			strRefs := make([]string, 0, len(refs[i]))
+			for _, ref := range refs[i] {
+				strRefs = append(strRefs, fmt.Sprintf("[%d]", ref))
+			}
+			_, _ = fmt.Fprintf(output, "License text: %s\n", strings.Join(strRefs, ", "))
+		}
+	}
+
+	separator := strings.Repeat("=", 75) + "\n"
+	for i, text := range texts {
+		output.WriteString("\n")
+		output.WriteString(separator)
+		_, _ = fmt.Fprintf(output, "[%d] %s\n", i+1, text.Name)
+		output.WriteString(separator)
+		output.WriteString("\n")
+		output.Write(bytes.TrimRight(text.Body, "\n"))
+		output.WriteString("\n")
+	}
+	return output
+}
+
+type htmlThirdPartyLicenses struct {
+	Dependencies []htmlThirdPartyDependency
+	Texts        []*htmlLicenseText
+}
+
+type htmlThirdPartyDependency struct {
+	Name       string
+	Version    string
+	Anchor     string
+	Licenses   []detectlicense.License
+	Copyrights []string
+	Texts      []*htmlLicenseText
+}
+
+type htmlLicenseText struct {
+	Number int
+	Name   string
+	Anchor string
+	Body   string
+}
+
+// thirdPartyLicensesHTMLOutput writes the same document as
+// thirdPartyLicensesOutput, but as a self-contained HTML page, in the
+// style of htmlOutput's; each dependency links to its license texts.
+func thirdPartyLicensesHTMLOutput(output *bytes.Buffer, deps []dependencyLicenseTexts) error {
+	texts, refs := numberLicenseTexts(deps)
+
+	var report htmlThirdPartyLicenses
+	for i, text := range texts {
+		report.Texts = append(report.Texts, &htmlLicenseText{
+			Number: i + 1,
+			Name:   text.Name,
+			Anchor: fmt.Sprintf("license-text-%d", i+1),
+			Body:   string(bytes.TrimRight(text.Body, "\n")),
+		})
+	}
+	for i, dependency := range deps {
+		htmlDep := htmlThirdPartyDependency{
+			Name:       dependency.Name,
+			Version:    dependency.Version,
+			Anchor:     htmlAnchor("dependency-", dependency.Name),
+			Licenses:   dependency.Licenses,
+			Copyrights: dependency.Copyrights,
+		}
+		for _, ref := range refs[i] {
+			htmlDep.Texts = append(htmlDep.Texts, report.Texts[ref-1])
+		}
+		report.Dependencies = append(report.Dependencies, htmlDep)
+	}
+
+	return htmlTemplates.ExecuteTemplate(output, "third_party_licenses.tmpl", report)
+}
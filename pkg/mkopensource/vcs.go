@@ -0,0 +1,44 @@
+package mkopensource
+
+import (
+	"github.com/go-git/go-git/v5"
+)
+
+// A VCS is the version control system that the scanned packages are
+// in.  The report of a scan is meant to be committed along with the
+// go.mod and go.sum that it was generated from, so a Scanner uses it to
+// check that they don't have uncommitted changes.
+type VCS interface {
+	// Modified returns whether any of the files called filenames,
+	// relative to the root of the working tree dir, have uncommitted
+	// changes.
+	Modified(dir string, filenames ...string) (bool, error)
+}
+
+// Git is the VCS for Git working trees.
+type Git struct{}
+
+// Modified opens the Git repository whose working tree is dir.
+func (Git) Modified(dir string, filenames ...string) (result bool, err error) {
+	var repo *git.Repository
+	if repo, err = git.PlainOpen(dir); err != nil {
+		return false, err
+	}
+
+	var worktree *git.Worktree
+	if worktree, err = repo.Worktree(); err != nil {
+		return false, err
+	}
+
+	var status git.Status
+	if status, err = worktree.Status(); err != nil {
+		return false, err
+	}
+
+	for _, filename := range filenames {
+		if status.File(filename).Worktree == git.Modified {
+			return true, nil
+		}
+	}
+	return false, nil
+}
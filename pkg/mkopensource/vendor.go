@@ -1,12 +1,12 @@
-package main
+package mkopensource
 
 // This file mimics the behavior of `go mod vendor`.
 
 import (
 	"io/fs"
-	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/datawire/go-mkopensource/pkg/golist"
 )
@@ -17,37 +17,57 @@ import (
 // filesystem access.
 
 type fsCache struct {
+	env *env
+
+	mu        sync.Mutex
 	fileCache map[string][]byte
 	dirCache  map[string][]fs.DirEntry
 }
 
-func newFSCache() *fsCache {
+func newFSCache(env *env) *fsCache {
 	return &fsCache{
+		env:       env,
 		fileCache: make(map[string][]byte),
 		dirCache:  make(map[string][]fs.DirEntry),
 	}
 }
 
+// readFile and readDir are safe to call from multiple goroutines.  Two
+// goroutines may both read the same file, if they ask for it at the
+// same time; that's cheaper than making one wait for the other.
+
 func (fs *fsCache) readFile(filename string) ([]byte, error) {
-	if _, done := fs.fileCache[filename]; !done {
-		body, err := os.ReadFile(filename)
+	fs.mu.Lock()
+	body, done := fs.fileCache[filename]
+	fs.mu.Unlock()
+	if !done {
+		var err error
+		body, err = fs.env.readFile(filename)
 		if err != nil {
 			return nil, err
 		}
+		fs.mu.Lock()
 		fs.fileCache[filename] = body
+		fs.mu.Unlock()
 	}
-	return fs.fileCache[filename], nil
+	return body, nil
 }
 
 func (fs *fsCache) readDir(dirname string) ([]fs.DirEntry, error) {
-	if _, done := fs.dirCache[dirname]; !done {
-		body, err := os.ReadDir(dirname)
+	fs.mu.Lock()
+	entries, done := fs.dirCache[dirname]
+	fs.mu.Unlock()
+	if !done {
+		var err error
+		entries, err = fs.env.readDir(dirname)
 		if err != nil {
 			return nil, err
 		}
-		fs.dirCache[dirname] = body
+		fs.mu.Lock()
+		fs.dirCache[dirname] = entries
+		fs.mu.Unlock()
 	}
-	return fs.dirCache[dirname], nil
+	return entries, nil
 }
 
 ////////////////////////////////////////////////////////////////////////
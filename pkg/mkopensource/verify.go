@@ -0,0 +1,232 @@
+package mkopensource
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/datawire/go-mkopensource/pkg/golist"
+)
+
+// The report is only as good as the files that it is generated from.
+// If somebody has edited a module in the module cache or in `vendor/`,
+// we would happily describe code that was never fetched from anywhere.
+// This file checks that the files that we scan are the pristine
+// contents of each module, as recorded in go.sum.
+
+const (
+	// What to do if a module's files don't match go.sum; see
+	// Options.VerifySources.
+	VerifyError = "error"
+	VerifyWarn  = "warn"
+	VerifyOff   = "off"
+)
+
+// verifyModuleSources checks the files of each (non-main) module in
+// listPkgs against go.sum, and returns the go.sum hash of each module
+// that was verified, keyed by module path.  Modules that are replaced
+// with a local directory have no go.sum entry, and are skipped.
+//
+// If vendored is false, the packages were read from the module cache,
+// and the module's directory is hashed as a whole.  If it is true, the
+// packages were read from `vendor/`, which only has some of the files
+// of each module, so instead each of the files in pkgFiles is compared
+// with the module's zip file (which is itself checked against go.sum);
+// see verifyVendoredModule.  Up to jobs modules are verified at once.
+func verifyModuleSources(env *env, listPkgs []golist.Package, mainMods map[string]struct{}, pkgFiles map[string]map[string][]byte,
+	vendored bool, goSum map[string]string, jobs int) (map[string]string, []error) {
+	var cache moduleCache
+	if vendored {
+		var err error
+		if cache, err = newModuleCache(env); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	modPkgs := make(map[string][]string)
+	modInfos := make(map[string]*golist.Module)
+	for _, pkg := range listPkgs {
+		if pkg.Module == nil {
+			continue
+		}
+		if _, isMainMod := mainMods[pkg.Module.Path]; isMainMod {
+			continue
+		}
+		if pkg.Module.Replace != nil && pkg.Module.Replace.Version == "" {
+			continue
+		}
+		modPkgs[pkg.Module.Path] = append(modPkgs[pkg.Module.Path], pkg.ImportPath)
+		modInfos[pkg.Module.Path] = pkg.Module
+	}
+	modNames := make([]string, 0, len(modInfos))
+	for modName := range modInfos {
+		modNames = append(modNames, modName)
+	}
+	sort.Strings(modNames)
+
+	modSums := make([]string, len(modNames))
+	modErrs := make([]error, len(modNames))
+	parallel(jobs, len(modNames), func(i int) {
+		modName := modNames[i]
+		if vendored {
+			modSums[i], modErrs[i] = verifyVendoredModule(env, cache, modInfos[modName], modPkgs[modName], pkgFiles, goSum)
+		} else {
+			modSums[i], modErrs[i] = verifyModuleDir(env, modInfos[modName], goSum)
+		}
+	})
+
+	sums := make(map[string]string, len(modNames))
+	var errs []error
+	for i, modName := range modNames {
+		if modErrs[i] != nil {
+			errs = append(errs, modErrs[i])
+			continue
+		}
+		sums[modName] = modSums[i]
+	}
+	return sums, errs
+}
+
+// verifyModuleDir checks that a module's directory in the module cache
+// has the hash that go.sum says it should.
+func verifyModuleDir(env *env, mod *golist.Module, goSum map[string]string) (string, error) {
+	srcPath, srcVersion := moduleSource(mod)
+	srcID := srcPath + "@" + srcVersion
+
+	expectedSum, ok := goSum[srcPath+" "+srcVersion]
+	if !ok {
+		return "", fmt.Errorf("module %s is missing from go.sum", srcID)
+	}
+	dir := mod.Dir
+	if mod.Replace != nil && mod.Replace.Dir != "" {
+		dir = mod.Replace.Dir
+	}
+	if dir == "" {
+		return "", fmt.Errorf("module %s: directory is not known", srcID)
+	}
+	dirSum, err := env.hashDir(dir, srcID)
+	if err != nil {
+		return "", err
+	}
+	if dirSum != expectedSum {
+		return "", fmt.Errorf("module %s: checksum mismatch: go.sum has %s, but %s has %s",
+			srcID, expectedSum, dir, dirSum)
+	}
+	return expectedSum, nil
+}
+
+// errNotVerified is the reason that a module couldn't be verified at
+// all; which is a warning, rather than an error, whatever
+// Options.VerifySources is.
+var errNotVerified = errors.New("not verified against go.sum")
+
+// moduleCache is where verifyVendoredModule looks for the zip files of
+// modules.
+type moduleCache struct {
+	// dir is $GOMODCACHE.
+	dir string
+	// offline is why modules that aren't in the module cache can't be
+	// downloaded, if they can't.
+	offline string
+}
+
+func newModuleCache(env *env) (moduleCache, error) {
+	vars, err := env.goTool.Env(env.dir, "GOMODCACHE", "GOPROXY", "GOFLAGS")
+	if err != nil {
+		return moduleCache{}, err
+	}
+	cache := moduleCache{dir: vars[0]}
+	if vars[1] == "off" {
+		cache.offline = "GOPROXY=off"
+	}
+	for _, flag := range strings.Fields(vars[2]) {
+		if flag == "-mod=vendor" || flag == "--mod=vendor" {
+			cache.offline = "GOFLAGS=" + vars[2]
+		}
+	}
+	return cache, nil
+}
+
+// verifyVendoredModule checks that the vendored files of each of the
+// packages of a module are identical to those in the module's zip
+// file.  The zip file is read straight from the module cache if it is
+// there, so that a vendored module doesn't need the network to be
+// verified; otherwise it is downloaded with `go mod download`, unless
+// the environment rules that out, in which case the module is skipped
+// with an errNotVerified.
+func verifyVendoredModule(env *env, cache moduleCache, mod *golist.Module, pkgNames []string, pkgFiles map[string]map[string][]byte,
+	goSum map[string]string) (string, error) {
+	srcPath, srcVersion := moduleSource(mod)
+	srcID := srcPath + "@" + srcVersion
+
+	expectedSum, ok := goSum[srcPath+" "+srcVersion]
+	if !ok {
+		return "", fmt.Errorf("module %s is missing from go.sum", srcID)
+	}
+	zipFilename := ""
+	if cache.dir != "" {
+		name, err := moduleCacheZip(cache.dir, srcPath, srcVersion)
+		if err != nil {
+			return "", err
+		}
+		if _, err := fs.Stat(env.fsys, env.fsName(name)); err == nil {
+			zipFilename = name
+		}
+	}
+	switch {
+	case zipFilename != "":
+		if err := checkModuleZip(env, srcID, zipFilename, expectedSum); err != nil {
+			return "", err
+		}
+	case cache.offline != "":
+		return "", fmt.Errorf("module %s is %w: it isn't in the module cache, and %s rules out downloading it",
+			srcID, errNotVerified, cache.offline)
+	default:
+		download, err := downloadModule(env, srcPath, srcVersion, goSum)
+		if err != nil {
+			return "", err
+		}
+		zipFilename = download.Zip
+	}
+	zipFiles, err := readModuleZip(env, zipFilename, srcID, mod.Path)
+	if err != nil {
+		return "", err
+	}
+
+	var mismatched []string
+	for _, pkgName := range pkgNames {
+		for filename, body := range pkgFiles[pkgName] {
+			if zipBody, ok := zipFiles[filename]; !ok || !bytes.Equal(body, zipBody) {
+				mismatched = append(mismatched, "vendor/"+filename)
+			}
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		return "", fmt.Errorf("module %s: files do not match the module as recorded in go.sum (%s): %s",
+			srcID, expectedSum, strings.Join(mismatched, ", "))
+	}
+	return expectedSum, nil
+}
+
+// reportVerifyErrors applies the Options.VerifySources mode to the
+// errors from verifyModuleSources: they are fatal for VerifyError, and
+// returned as warnings for VerifyWarn.  Modules that couldn't be
+// verified at all (errNotVerified) are always warnings.
+func reportVerifyErrors(mode string, errs []error) (warnings []error, err error) {
+	var fatal []error
+	for _, err := range errs {
+		if mode == VerifyError && !errors.Is(err, errNotVerified) {
+			fatal = append(fatal, err)
+		} else {
+			warnings = append(warnings, err)
+		}
+	}
+	if len(fatal) > 0 {
+		return nil, fmt.Errorf("could not verify module sources against go.sum:\n%w", errors.Join(fatal...))
+	}
+	return warnings, nil
+}
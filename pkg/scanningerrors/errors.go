@@ -0,0 +1,110 @@
+package scanningerrors
+
+import (
+	"fmt"
+)
+
+// The errors that scanning a dependency can produce.  They are all
+// pointer types, so that callers can pick them out of a (possibly
+// wrapped) error with errors.As:
+//
+//	var forbidden *scanningerrors.ForbiddenLicenseError
+//	if errors.As(err, &forbidden) {
+//		...
+//	}
+
+// DetectionError is returned when the license of a dependency can't be
+// confidently detected.
+type DetectionError struct {
+	Name    string
+	Version string
+	// Files are the files that no license could be identified in: a
+	// license file that isn't recognized, or source files with no
+	// license header when there is no license file to cover them.
+	Files  []string
+	Reason string
+}
+
+func (e *DetectionError) Error() string {
+	return e.Reason
+}
+
+// UnknownSPDXError is returned when a dependency declares a license
+// with an SPDX identifier that we don't know about.
+type UnknownSPDXError struct {
+	Name    string
+	Version string
+	// File is the source file that has the SPDX-License-Identifier
+	// tag; it is empty if the identifier was declared in the
+	// dependency's package metadata (such as package.json) instead.
+	File string
+	ID   string
+}
+
+func (e *UnknownSPDXError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("Dependency '%s@%s' has an unknown SPDX Identifier '%s'.", e.Name, e.Version, e.ID)
+	}
+	return fmt.Sprintf("unknown SPDX identifier %q", e.ID)
+}
+
+// ForbiddenLicenseError is returned when a dependency uses a license
+// that isn't allowed anywhere.
+type ForbiddenLicenseError struct {
+	Name    string
+	Version string
+	License string
+	// SourceAvailable is whether the license is a source-available
+	// (as opposed to opensource) license.
+	SourceAvailable bool
+}
+
+func (e *ForbiddenLicenseError) Error() string {
+	if e.SourceAvailable {
+		return fmt.Sprintf("Dependency '%s@%s' uses license '%s' which is source-available, not open-source, and is forbidden.",
+			e.Name, e.Version, e.License)
+	}
+	return fmt.Sprintf("Dependency '%s@%s' uses license '%s' which is forbidden.", e.Name, e.Version, e.License)
+}
+
+// RestrictedLicenseError is returned when a dependency uses a license
+// that is only allowed for applications that don't get distributed.
+type RestrictedLicenseError struct {
+	Name    string
+	Version string
+	License string
+}
+
+func (e *RestrictedLicenseError) Error() string {
+	return fmt.Sprintf("Dependency '%s@%s' uses license '%s' which is not allowed on applications that run on customer machines.",
+		e.Name, e.Version, e.License)
+}
+
+// AnomalyError is returned when the license metadata of a dependency
+// doesn't add up, such as a NOTICE file next to a license that has no
+// use for one, or a PATENTS file next to a license that already has a
+// patent grant.
+type AnomalyError struct {
+	Name     string
+	Version  string
+	Files    []string
+	Licenses []string
+	Reason   string
+}
+
+func (e *AnomalyError) Error() string {
+	return e.Reason
+}
+
+// StaleOverrideError is returned when a dependency that has its license
+// overridden in an --unparsable-packages file has a license that can
+// be detected after all, and so the override should be removed.
+type StaleOverrideError struct {
+	Name    string
+	Version string
+	File    string
+}
+
+func (e *StaleOverrideError) Error() string {
+	return fmt.Sprintf("Package %q has a valid license. It must be removed from %s", e.Name, e.File)
+}
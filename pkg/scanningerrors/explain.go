@@ -14,19 +14,29 @@ const (
 	licenseDetection  string = "license-detection"
 	internalUsageOnly string = "intended-usage"
 	licenseForbidden  string = "license-forbidden"
+	sourceAvailable   string = "license-source-available"
+	staleOverride     string = "stale-override"
 )
 
-func categorizeError(errStr string) string {
+func categorizeError(err error) string {
+	var anomaly *AnomalyError
+	var forbidden *ForbiddenLicenseError
+	var restricted *RestrictedLicenseError
+	var stale *StaleOverrideError
 	switch {
-	case strings.Contains(errStr, "something hokey is going on"):
+	case errors.As(err, &anomaly):
 		return licenseIssue
-	case strings.Contains(errStr, "is missing a license identifier"):
-		return licenseDetection
-	case strings.Contains(errStr, "is forbidden"):
+	case errors.As(err, &forbidden):
+		if forbidden.SourceAvailable {
+			return sourceAvailable
+		}
 		return licenseForbidden
-	case strings.Contains(errStr, "which is not allowed on applications"):
+	case errors.As(err, &restricted):
 		return internalUsageOnly
+	case errors.As(err, &stale):
+		return staleOverride
 	default:
+		// *DetectionError, *UnknownSPDXError, or anything else
 		return licenseDetection
 	}
 }
@@ -47,32 +57,103 @@ var errCategoryExplanations = map[string]string{
 
 		Some possible causes for  this issue are:
 
-		- Dependency is proprietary Ambassador Labs software: Create a yaml file with the proprietary 
+		- Dependency is proprietary $ORGANIZATION software: Create a yaml file with the proprietary 
           dependencies and pass it to the generate.sh script using the --proprietary-packages command line option.
           See the README.md file for more information.
 
-		- License information can't be identified: Add an entry to 
-          hardcodedGoDependencies, hardcodedPythonDependencies 
-          or hardcodedJsDependencies depending on the dependency that
-          was not identified.`,
+		- License information can't be identified: Add an entry for the dependency
+          that was not identified to a license database, and pass it to the
+          generate.sh script using the --license-database command line option.
+          See the README.md file for more information.`,
+
+	internalUsageOnly: `To solve this error, replace the dependency with another that uses an acceptable license.`,
+
+	licenseForbidden: `To solve this error, replace the dependency with another that uses an acceptable license.`,
+
+	sourceAvailable: `This dependency's source code is public, but it is not open-source: its license
+		(SSPL, BUSL, Elastic License, Commons Clause, PolyForm, ...) restricts hosting, selling,
+		competing with, or commercial use of the software.
+
+		This usually happens after a relicensing event: the project was open-source, and a
+		newer version (possibly of a transitive dependency) moved to a source-available
+		license.  Check whether you just upgraded it; pinning the last open-source version,
+		or moving to an open-source fork of it, is usually the way out.  Otherwise, replace
+		the dependency with another that uses an acceptable license.`,
+
+	staleOverride: `This means that a package that has its license hard-coded in the
+		--unparsable-packages file now has a license that the checker can detect on its own,
+		probably because it was upgraded.  Remove the package from that file, so that its
+		license gets checked again the next time it changes.`,
+}
+
+// referToPolicy are the categories whose explanation refers to the
+// license policy.
+//
+//nolint:gochecknoglobals  //golang doesn't const maps so ignore linting
+var referToPolicy = map[string]bool{
+	internalUsageOnly: true,
+	licenseForbidden:  true,
+	sourceAvailable:   true,
+}
+
+// errHint returns advice that is specific to a single error, to go
+// along with the explanation of its category.
+func errHint(err error) string {
+	var detection *DetectionError
+	if errors.As(err, &detection) && detection.Name == "github.com/josharian/intern" {
+		return `
+
+			For github.com/josharian/intern in particular, this probably
+			means that you are depending on an old version; upgrading to
+			intern v1.0.1-0.20211109044230-42b52b674af5 or later should
+			resolve this.`
+	}
+	return ""
+}
 
-	internalUsageOnly: `To solve this error, replace the dependency with another that uses an acceptable license.
+// explainedErrors is the error returned by ExplainErrors.
+type explainedErrors struct {
+	msg  string
+	errs []error
+}
 
-        Refer to https://www.notion.so/datawire/License-Management-5194ca50c9684ff4b301143806c92157#1cd50aeeafa7456bba24c761c0a2d173 
-        for more details.`,
+func (e *explainedErrors) Error() string {
+	return e.msg
+}
 
-	licenseForbidden: `To solve this error, replace the dependency with another that uses an acceptable license.
+func (e *explainedErrors) Unwrap() []error {
+	return e.errs
+}
 
-        Refer to https://www.notion.so/datawire/License-Management-5194ca50c9684ff4b301143806c92157#1cd50aeeafa7456bba24c761c0a2d173 
-        for more details.`,
+// Errors returns the individual errors that ExplainErrors combined in
+// to err, or nil if err didn't come from ExplainErrors.
+func Errors(err error) []error {
+	var explained *explainedErrors
+	if !errors.As(err, &explained) {
+		return nil
+	}
+	return explained.errs
 }
 
+// ExplainErrors combines the errors from scanning the dependencies in
+// to a single error, grouped by category, with an explanation of what
+// to do about each category.  The explanations refer to the Policy of
+// SetPolicy.
 func ExplainErrors(errs []error) error {
+	return CurrentPolicy().ExplainErrors(errs)
+}
+
+// ExplainErrors is like the function of the same name, but the
+// explanations refer to p.
+func (p Policy) ExplainErrors(errs []error) error {
 	buckets := make(map[string][]string)
+	hints := make(map[string]string)
 	for _, err := range errs {
-		errStr := err.Error()
-		cat := categorizeError(errStr)
-		buckets[cat] = append(buckets[cat], errStr)
+		cat := categorizeError(err)
+		buckets[cat] = append(buckets[cat], err.Error())
+		if hint := errHint(err); hint != "" {
+			hints[cat] = hint
+		}
 	}
 
 	cats := make([]string, 0, len(buckets))
@@ -83,7 +164,7 @@ func ExplainErrors(errs []error) error {
 
 	msg := new(strings.Builder)
 	for _, cat := range cats {
-		explanation := errCategoryExplanations[cat]
+		explanation := p.categoryExplanation(cat) + hints[cat]
 		errStrs := buckets[cat]
 		if len(errs) == 1 {
 			_, _ = fmt.Fprintf(msg, "1 %s error:\n", cat)
@@ -93,16 +174,11 @@ func ExplainErrors(errs []error) error {
 		}
 		for i, errStr := range errStrs {
 			_, _ = fmt.Fprintf(msg, " %d. %s\n", i+1, errStr)
-			if errStr == `Package "github.com/josharian/intern": could not identify a license for all sources (had no global LICENSE file)` {
-				explanation += `
-
-					For github.com/josharian/intern in particular, this probably
-					means that you are depending on an old version; upgrading to
-					intern v1.0.1-0.20211109044230-42b52b674af5 or later should
-					resolve this.`
-			}
 		}
 		_, _ = fmt.Fprintln(msg, Wordwrap(4, 72, explanation))
 	}
-	return errors.New(strings.TrimRight(msg.String(), "\n"))
+	return &explainedErrors{
+		msg:  strings.TrimRight(msg.String(), "\n"),
+		errs: errs,
+	}
 }
@@ -0,0 +1,55 @@
+package scanningerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorizeError(t *testing.T) {
+	testcases := map[string]struct {
+		Input            error
+		ExpectedCategory string
+	}{
+		"detection": {
+			Input:            &DetectionError{Name: "example.com/lib", Reason: "could not identify license in file \"LICENSE\""},
+			ExpectedCategory: licenseDetection,
+		},
+		"wrapped": {
+			Input:            fmt.Errorf("Package %q: %w", "example.com/lib", &UnknownSPDXError{ID: "Foo"}),
+			ExpectedCategory: licenseDetection,
+		},
+		"anomaly": {
+			Input:            fmt.Errorf("Package %q: %w", "example.com/lib", &AnomalyError{Reason: "something hokey is going on"}),
+			ExpectedCategory: licenseIssue,
+		},
+		"forbidden": {
+			Input:            &ForbiddenLicenseError{Name: "example.com/lib", License: "AGPL"},
+			ExpectedCategory: licenseForbidden,
+		},
+		"source-available": {
+			Input:            &ForbiddenLicenseError{Name: "example.com/lib", License: "SSPL", SourceAvailable: true},
+			ExpectedCategory: sourceAvailable,
+		},
+		"restricted": {
+			Input:            &RestrictedLicenseError{Name: "example.com/lib", License: "GPL"},
+			ExpectedCategory: internalUsageOnly,
+		},
+		"stale-override": {
+			Input:            &StaleOverrideError{Name: "example.com/lib", File: "unparsable-packages.yaml"},
+			ExpectedCategory: staleOverride,
+		},
+		"untyped": {
+			Input:            errors.New("is forbidden"),
+			ExpectedCategory: licenseDetection,
+		},
+	}
+	for tcName, tcData := range testcases {
+		tcData := tcData
+		t.Run(tcName, func(t *testing.T) {
+			assert.Equal(t, tcData.ExpectedCategory, categorizeError(tcData.Input))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package scanningerrors
+
+import (
+	"strings"
+	"sync"
+)
+
+// Policy is what the explanations of the errors say about the
+// organization whose license policy the dependencies are checked
+// against.
+type Policy struct {
+	// Organization is the name of the organization, such as
+	// "Ambassador Labs".  If it is empty, the organization's own
+	// software is referred to as "first-party" software.
+	Organization string
+	// URL is where the license policy is documented, if anywhere.
+	URL string
+}
+
+//nolint:gochecknoglobals // Set once, by SetPolicy.
+var (
+	policyMu sync.RWMutex
+	policy   Policy
+)
+
+// SetPolicy sets the Policy that ExplainErrors and NewReport refer to.
+// A program that checks against more than one Policy uses the Policy's
+// own methods instead.
+func SetPolicy(p Policy) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	policy = p
+}
+
+// CurrentPolicy returns the Policy that was last set by SetPolicy.
+func CurrentPolicy() Policy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return policy
+}
+
+// categoryExplanation returns the explanation of a category of errors, for the
+// Policy.
+func (p Policy) categoryExplanation(cat string) string {
+	organization := p.Organization
+	if organization == "" {
+		organization = "first-party"
+	}
+	ret := strings.ReplaceAll(errCategoryExplanations[cat], "$ORGANIZATION", organization)
+	if p.URL != "" && referToPolicy[cat] {
+		ret += "\n\nRefer to " + p.URL + " for more details."
+	}
+	return ret
+}
@@ -0,0 +1,37 @@
+package scanningerrors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy(t *testing.T) {
+	defer SetPolicy(Policy{})
+
+	errs := []error{
+		&DetectionError{Name: "example.com/lib", Reason: "could not identify license in file \"LICENSE\""},
+		&ForbiddenLicenseError{Name: "example.com/agpl", Version: "v1.0.0", License: "AGPL"},
+	}
+
+	// No policy
+	msg := ExplainErrors(errs).Error()
+	assert.Contains(t, msg, "Dependency is proprietary first-party software")
+	assert.NotContains(t, msg, "Refer to")
+
+	SetPolicy(Policy{Organization: "Example Corp", URL: "https://example.com/license-policy"})
+	msg = ExplainErrors(errs).Error()
+	assert.Contains(t, msg, "Dependency is proprietary Example Corp software")
+	assert.Contains(t, msg, "    an acceptable license.\n\n    Refer to https://example.com/license-policy for more details.")
+
+	report := NewReport(errs, nil)
+	assert.Equal(t, "To solve this error, replace the dependency with another that uses an acceptable license.\n\n"+
+		"Refer to https://example.com/license-policy for more details.", report.Errors[1].Remediation)
+
+	// A Policy's own methods don't depend on SetPolicy.
+	msg = Policy{Organization: "Other Corp"}.ExplainErrors(errs).Error()
+	assert.Contains(t, msg, "Dependency is proprietary Other Corp software")
+	assert.NotContains(t, msg, "Refer to")
+	report = Policy{}.NewReport(errs, nil)
+	assert.Equal(t, "To solve this error, replace the dependency with another that uses an acceptable license.", report.Errors[1].Remediation)
+}
@@ -0,0 +1,255 @@
+package scanningerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+)
+
+// Report is a machine-readable version of what ExplainErrors says, for
+// tools (code-review bots, dashboards, ...) that need to act on
+// scanning errors without parsing English sentences.
+type Report struct {
+	Errors []ReportEntry `json:"errors"`
+
+	// policy is what the SARIF rules' help refers to.
+	policy Policy
+}
+
+// ReportEntry is a single scanning error.
+type ReportEntry struct {
+	Category    string    `json:"category"`
+	Dependency  string    `json:"dependency,omitempty"`
+	Version     string    `json:"version,omitempty"`
+	Files       []string  `json:"files,omitempty"`
+	Licenses    []string  `json:"licenses,omitempty"`
+	Message     string    `json:"message"`
+	Remediation string    `json:"remediation"`
+	Location    *Location `json:"location,omitempty"`
+}
+
+// Location is where a dependency is declared, such as its line in
+// go.mod or package.json.
+type Location struct {
+	File string `json:"file"`
+	// Line is 1-based; it is 0 if the dependency isn't declared in
+	// File directly (for instance, if it is an indirect dependency).
+	Line int `json:"line,omitempty"`
+}
+
+// A Locator returns where a dependency is declared, or nil if it
+// doesn't know.
+type Locator func(dependency string) *Location
+
+//nolint:gochecknoglobals  //golang doesn't const maps so ignore linting
+var errCategoryTitles = map[string]string{
+	licenseIssue:      "The license metadata of a dependency doesn't add up",
+	licenseDetection:  "The license of a dependency could not be detected",
+	internalUsageOnly: "A dependency uses a license that is not allowed on applications that run on customer machines",
+	licenseForbidden:  "A dependency uses a forbidden license",
+	sourceAvailable:   "A dependency uses a source-available license",
+	staleOverride:     "A dependency has a license override that is no longer needed",
+}
+
+// NewReport builds a Report of the errors from scanning the
+// dependencies.  locate may be nil.  The remediations refer to the
+// Policy of SetPolicy.
+func NewReport(errs []error, locate Locator) Report {
+	return CurrentPolicy().NewReport(errs, locate)
+}
+
+// NewReport is like the function of the same name, but the
+// remediations refer to p.
+func (p Policy) NewReport(errs []error, locate Locator) Report {
+	report := Report{Errors: make([]ReportEntry, 0, len(errs)), policy: p}
+	for _, err := range errs {
+		cat := categorizeError(err)
+		entry := ReportEntry{
+			Category:    cat,
+			Message:     err.Error(),
+			Remediation: unwrap(p.categoryExplanation(cat) + errHint(err)),
+		}
+		entry.Dependency, entry.Version, entry.Files, entry.Licenses = errDetails(err)
+		if locate != nil && entry.Dependency != "" {
+			entry.Location = locate(entry.Dependency)
+		}
+		report.Errors = append(report.Errors, entry)
+	}
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		if report.Errors[i].Category != report.Errors[j].Category {
+			return report.Errors[i].Category < report.Errors[j].Category
+		}
+		return report.Errors[i].Message < report.Errors[j].Message
+	})
+	return report
+}
+
+// errDetails returns what a typed scanning error knows about the
+// dependency that it is about.
+func errDetails(err error) (name, version string, files, licenses []string) {
+	var detection *DetectionError
+	var spdx *UnknownSPDXError
+	var forbidden *ForbiddenLicenseError
+	var restricted *RestrictedLicenseError
+	var anomaly *AnomalyError
+	var stale *StaleOverrideError
+	switch {
+	case errors.As(err, &detection):
+		return detection.Name, detection.Version, detection.Files, nil
+	case errors.As(err, &spdx):
+		if spdx.File != "" {
+			files = []string{spdx.File}
+		}
+		return spdx.Name, spdx.Version, files, nil
+	case errors.As(err, &forbidden):
+		return forbidden.Name, forbidden.Version, nil, []string{forbidden.License}
+	case errors.As(err, &restricted):
+		return restricted.Name, restricted.Version, nil, []string{restricted.License}
+	case errors.As(err, &anomaly):
+		return anomaly.Name, anomaly.Version, anomaly.Files, anomaly.Licenses
+	case errors.As(err, &stale):
+		return stale.Name, stale.Version, []string{stale.File}, nil
+	default:
+		return "", "", nil, nil
+	}
+}
+
+// unwrap undoes the hard line-wrapping of an explanation, leaving one
+// line per paragraph.
+func unwrap(explanation string) string {
+	return Wordwrap(0, math.MaxInt32, explanation)
+}
+
+// JSON returns the report as indented JSON.
+func (r Report) JSON() ([]byte, error) {
+	body, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(body, '\n'), nil
+}
+
+// The subset of SARIF 2.1.0 (https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html)
+// that we use.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	Help             sarifMessage `json:"help"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations,omitempty"`
+	Properties sarifProperties `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifProperties struct {
+	Dependency  string   `json:"dependency,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Files       []string `json:"files,omitempty"`
+	Licenses    []string `json:"licenses,omitempty"`
+	Remediation string   `json:"remediation"`
+}
+
+// SARIF returns the report as a SARIF log, with a rule for each
+// category of error.  toolName is the name of the program that did the
+// scanning.
+func (r Report) SARIF(toolName string) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			InformationURI: "https://github.com/datawire/go-mkopensource",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	haveRule := make(map[string]bool)
+	for _, entry := range r.Errors {
+		if !haveRule[entry.Category] {
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               entry.Category,
+				ShortDescription: sarifMessage{Text: errCategoryTitles[entry.Category]},
+				Help:             sarifMessage{Text: unwrap(r.policy.categoryExplanation(entry.Category))},
+			})
+			haveRule[entry.Category] = true
+		}
+		result := sarifResult{
+			RuleID:  entry.Category,
+			Level:   "error",
+			Message: sarifMessage{Text: entry.Message},
+			Properties: sarifProperties{
+				Dependency:  entry.Dependency,
+				Version:     entry.Version,
+				Files:       entry.Files,
+				Licenses:    entry.Licenses,
+				Remediation: entry.Remediation,
+			},
+		}
+		if entry.Location != nil {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: entry.Location.File, URIBaseID: "%SRCROOT%"},
+			}}
+			if entry.Location.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: entry.Location.Line}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	body, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(body, '\n'), nil
+}
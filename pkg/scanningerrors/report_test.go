@@ -0,0 +1,57 @@
+package scanningerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReport(t *testing.T) {
+	errs := []error{
+		errors.New("Could not generate list of license URLs"),
+		&ForbiddenLicenseError{Name: "example.com/agpl", Version: "v1.0.0", License: "GNU Affero General Public License v3.0 or later"},
+		fmt.Errorf("Package %q: %w", "example.com/lib/sub", &UnknownSPDXError{
+			Name:    "example.com/lib/sub",
+			Version: "v1.2.3",
+			File:    "example.com/lib/sub/sub.go",
+			ID:      "Foo",
+		}),
+	}
+	locate := func(dependency string) *Location {
+		if dependency == "example.com/agpl" {
+			return &Location{File: "go.mod", Line: 5}
+		}
+		return &Location{File: "go.mod"}
+	}
+
+	// ExplainErrors keeps the individual errors around for the report
+	require.Equal(t, errs, Errors(ExplainErrors(errs)))
+	require.Nil(t, Errors(errors.New("some other error")))
+
+	report := NewReport(errs, locate)
+	require.Len(t, report.Errors, 3)
+
+	// Sorted by category, then message
+	assert.Equal(t, licenseDetection, report.Errors[0].Category)
+	assert.Equal(t, "Could not generate list of license URLs", report.Errors[0].Message)
+	assert.Empty(t, report.Errors[0].Dependency)
+	assert.Nil(t, report.Errors[0].Location)
+
+	assert.Equal(t, licenseDetection, report.Errors[1].Category)
+	assert.Equal(t, "example.com/lib/sub", report.Errors[1].Dependency)
+	assert.Equal(t, []string{"example.com/lib/sub/sub.go"}, report.Errors[1].Files)
+	assert.Equal(t, &Location{File: "go.mod"}, report.Errors[1].Location)
+
+	assert.Equal(t, ReportEntry{
+		Category:    licenseForbidden,
+		Dependency:  "example.com/agpl",
+		Version:     "v1.0.0",
+		Licenses:    []string{"GNU Affero General Public License v3.0 or later"},
+		Message:     "Dependency 'example.com/agpl@v1.0.0' uses license 'GNU Affero General Public License v3.0 or later' which is forbidden.",
+		Remediation: unwrap(errCategoryExplanations[licenseForbidden]),
+		Location:    &Location{File: "go.mod", Line: 5},
+	}, report.Errors[2])
+}